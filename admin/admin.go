@@ -0,0 +1,192 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// DefaultRecentFrames 每个机器人默认保留的最近帧数量
+const DefaultRecentFrames = 100
+
+// BotInfo 机器人连接信息
+type BotInfo struct {
+	BotId        int64     `json:"bot_id"`
+	RemoteAddr   string    `json:"remote_addr"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	Encoding     string    `json:"encoding"`
+	QueueDepth   int       `json:"queue_depth"`
+	PendingCalls int       `json:"pending_calls"`
+}
+
+// FrameRecord 最近收发的帧，Direction 为 in 或 out
+type FrameRecord struct {
+	Time      time.Time     `json:"time"`
+	Direction string        `json:"direction"`
+	FrameType string        `json:"frame_type"`
+	Echo      string        `json:"echo,omitempty"`
+	Frame     *onebot.Frame `json:"frame"`
+}
+
+// Admin 调试用的 http.Handler，可以通过 http.StripPrefix 挂载到任意路径，本身不做鉴权
+//
+//	GET  /bots                   机器人列表
+//	GET  /bots/{id}/frames       最近收发的帧
+//	POST /bots/{id}/disconnect   强制断开连接
+type Admin struct {
+	// RecentFrames 每个机器人保留的最近帧数量
+	RecentFrames int
+
+	mu     sync.Mutex
+	frames map[int64][]*FrameRecord
+}
+
+// New recentFrames 小于等于 0 时使用 DefaultRecentFrames
+func New(recentFrames int) *Admin {
+	if recentFrames <= 0 {
+		recentFrames = DefaultRecentFrames
+	}
+	return &Admin{
+		RecentFrames: recentFrames,
+		frames:       make(map[int64][]*FrameRecord),
+	}
+}
+
+// Install 注册拦截器记录收发的帧，并在机器人断开时清除记录，只需要调用一次
+func (a *Admin) Install() {
+	handleDisconnect := pbbot.HandleDisconnect
+	pbbot.HandleDisconnect = func(bot *pbbot.Bot) {
+		a.remove(bot)
+		handleDisconnect(bot)
+	}
+	pbbot.UseFrameInterceptor(func(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+		a.record(bot, "in", frame)
+		next(ctx)
+	})
+	pbbot.UseApiInterceptor(func(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+		a.record(bot, "out", req)
+		return next(ctx)
+	})
+}
+
+// record 断开后不再记录，避免 remove 之后重新创建
+func (a *Admin) record(bot *pbbot.Bot, direction string, frame *onebot.Frame) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	select {
+	case <-bot.Session.Done():
+		return
+	default:
+	}
+	records := append(a.frames[bot.BotId], &FrameRecord{
+		Time:      time.Now(),
+		Direction: direction,
+		FrameType: frame.FrameType.String(),
+		Echo:      frame.Echo,
+		Frame:     frame,
+	})
+	if len(records) > a.RecentFrames {
+		records = append(records[:0:0], records[len(records)-a.RecentFrames:]...)
+	}
+	a.frames[bot.BotId] = records
+}
+
+// remove 同一 BotId 已经重新连接时保留记录
+func (a *Admin) remove(bot *pbbot.Bot) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if current, ok := pbbot.GetBot(bot.BotId); ok && current != bot {
+		return
+	}
+	delete(a.frames, bot.BotId)
+}
+
+// Frames 机器人最近收发的帧，按时间顺序
+func (a *Admin) Frames(botId int64) []*FrameRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]*FrameRecord(nil), a.frames[botId]...)
+}
+
+// Bots 已连接的机器人，按 BotId 排序
+func Bots() []*BotInfo {
+	bots := pbbot.ListBots()
+	infos := make([]*BotInfo, 0, len(bots))
+	for _, bot := range bots {
+		infos = append(infos, &BotInfo{
+			BotId:        bot.BotId,
+			RemoteAddr:   bot.RemoteAddr,
+			ConnectedAt:  bot.ConnectedAt,
+			Encoding:     bot.Session.Encoding(),
+			QueueDepth:   bot.Session.QueueDepth(),
+			PendingCalls: bot.PendingCalls(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].BotId < infos[j].BotId })
+	return infos
+}
+
+func (a *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 0 || parts[0] != "bots" {
+		http.NotFound(w, r)
+		return
+	}
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, Bots())
+		return
+	}
+	if len(parts) != 3 {
+		http.NotFound(w, r)
+		return
+	}
+	botId, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid bot id", http.StatusBadRequest)
+		return
+	}
+	switch parts[2] {
+	case "frames":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, a.Frames(botId))
+	case "disconnect":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		bot, ok := pbbot.GetBot(botId)
+		if !ok {
+			http.Error(w, "bot not connected", http.StatusNotFound)
+			return
+		}
+		if err := bot.Disconnect(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		bot.Logger.Log(pbbot.LevelWarn, "bot disconnected by admin", pbbot.F("remote_addr", r.RemoteAddr))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
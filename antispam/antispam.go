@@ -0,0 +1,198 @@
+package antispam
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/util"
+)
+
+// Action 触发刷屏或重复消息后的处理，可以组合使用
+type Action int
+
+const (
+	ActionDrop   Action = 1 << iota // 丢弃消息，不再交给后续的处理函数
+	ActionWarn                      // @发送者警告，每个窗口只警告一次
+	ActionRecall                    // 撤回消息
+	ActionBan                       // 禁言 BanDuration
+)
+
+// Config 单个群的配置，Window 内同一用户发送超过 MaxMessages 条消息视为刷屏，相同内容超过 MaxDuplicates 条视为重复消息
+type Config struct {
+	Enabled       bool
+	Window        time.Duration
+	MaxMessages   int // 0 表示不检测刷屏
+	MaxDuplicates int // 0 表示不检测重复消息
+	Actions       Action
+	BanDuration   time.Duration
+	WarnMessage   string
+	AllowAdmins   bool           // 群管理员、群主和超级用户不检测
+	Allowlist     map[int64]bool // 不检测的用户
+}
+
+var DefaultConfig = Config{
+	Enabled:       true,
+	Window:        10 * time.Second,
+	MaxMessages:   8,
+	MaxDuplicates: 4,
+	Actions:       ActionDrop | ActionWarn,
+	BanDuration:   10 * time.Minute,
+	WarnMessage:   "请不要刷屏",
+	AllowAdmins:   true,
+}
+
+type userKey struct {
+	botId   int64
+	groupId int64
+	userId  int64
+}
+
+type userState struct {
+	times    []time.Time
+	contents []string
+	warnedAt time.Time
+}
+
+// Guard 群消息刷屏检测，通过 Middleware 接入消息处理
+type Guard struct {
+	mu      sync.Mutex
+	config  Config
+	groups  map[int64]Config
+	states  map[userKey]*userState
+	checked int
+}
+
+func NewGuard(config Config) *Guard {
+	return &Guard{
+		config: config,
+		groups: make(map[int64]Config),
+		states: make(map[userKey]*userState),
+	}
+}
+
+// SetGroupConfig 设置单个群的配置，覆盖默认配置
+func (g *Guard) SetGroupConfig(groupId int64, config Config) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.groups[groupId] = config
+}
+
+// RemoveGroupConfig 恢复使用默认配置
+func (g *Guard) RemoveGroupConfig(groupId int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.groups, groupId)
+}
+
+func (g *Guard) GroupConfig(groupId int64) Config {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.groupConfigLocked(groupId)
+}
+
+func (g *Guard) groupConfigLocked(groupId int64) Config {
+	if config, ok := g.groups[groupId]; ok {
+		return config
+	}
+	return g.config
+}
+
+// Middleware 只检测群消息
+func (g *Guard) Middleware() pbbot.MessageMiddleware {
+	return func(ctx *pbbot.MessageContext, next func()) {
+		if !ctx.IsGroup() {
+			next()
+			return
+		}
+		config := g.GroupConfig(ctx.GroupId)
+		if !config.Enabled || config.Allowlist[ctx.UserId] || (config.AllowAdmins && ctx.HasPermission(pbbot.PermissionGroupAdmin)) {
+			next()
+			return
+		}
+		reason, warn := g.check(ctx, config)
+		if reason == "" {
+			next()
+			return
+		}
+		ctx.Bot.Logger.Log(pbbot.LevelWarn, "antispam triggered", pbbot.F(pbbot.FieldGroupId, ctx.GroupId), pbbot.F(pbbot.FieldUserId, ctx.UserId), pbbot.F("reason", reason))
+		g.punish(ctx, config, warn)
+		if config.Actions&ActionDrop == 0 {
+			next()
+		}
+	}
+}
+
+// check 记录消息并返回触发原因，warn 表示本窗口内还没有警告过
+func (g *Guard) check(ctx *pbbot.MessageContext, config Config) (reason string, warn bool) {
+	now := time.Now()
+	key := userKey{ctx.Bot.BotId, ctx.GroupId, ctx.UserId}
+	content := ctx.Msg.ToCQ()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.checked++
+	if g.checked%1000 == 0 {
+		g.pruneLocked(now)
+	}
+	state, ok := g.states[key]
+	if !ok {
+		state = &userState{}
+		g.states[key] = state
+	}
+	expired := 0
+	for expired < len(state.times) && now.Sub(state.times[expired]) > config.Window {
+		expired++
+	}
+	state.times = append(state.times[expired:], now)
+	state.contents = append(state.contents[expired:], content)
+
+	if config.MaxMessages > 0 && len(state.times) > config.MaxMessages {
+		reason = "flood"
+	} else if config.MaxDuplicates > 0 {
+		duplicates := 0
+		for _, c := range state.contents {
+			if c == content {
+				duplicates++
+			}
+		}
+		if duplicates > config.MaxDuplicates {
+			reason = "duplicate"
+		}
+	}
+	if reason != "" && now.Sub(state.warnedAt) > config.Window {
+		state.warnedAt = now
+		warn = true
+	}
+	return reason, warn
+}
+
+func (g *Guard) punish(ctx *pbbot.MessageContext, config Config, warn bool) {
+	util.SafeGo(func() {
+		if config.Actions&ActionRecall != 0 {
+			if err := ctx.Recall(); err != nil {
+				ctx.Bot.Logger.Log(pbbot.LevelError, "antispam failed to recall message", pbbot.Err(err))
+			}
+		}
+		if config.Actions&ActionBan != 0 && warn {
+			if err := ctx.BanSender(int32(config.BanDuration / time.Second)); err != nil {
+				ctx.Bot.Logger.Log(pbbot.LevelError, "antispam failed to ban sender", pbbot.Err(err))
+			}
+		}
+		if config.Actions&ActionWarn != 0 && warn && config.WarnMessage != "" {
+			if _, err := ctx.ReplyAt(pbbot.NewMsg().Text(config.WarnMessage)); err != nil {
+				ctx.Bot.Logger.Log(pbbot.LevelError, "antispam failed to warn sender", pbbot.Err(err))
+			}
+		}
+	})
+}
+
+// pruneLocked 删除窗口外没有消息的用户状态
+func (g *Guard) pruneLocked(now time.Time) {
+	for key, state := range g.states {
+		config := g.groupConfigLocked(key.groupId)
+		if len(state.times) == 0 || now.Sub(state.times[len(state.times)-1]) > config.Window {
+			delete(g.states, key)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package approval
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// Outcome 请求的处理结果
+type Outcome int
+
+const (
+	OutcomeIgnore      Outcome = iota // 不处理
+	OutcomeApprove                    // 同意
+	OutcomeReject                     // 拒绝
+	OutcomeNotifyAdmin                // 通知管理员，由管理员通过 Policy.Resolve 处理
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeApprove:
+		return "approve"
+	case OutcomeReject:
+		return "reject"
+	case OutcomeNotifyAdmin:
+		return "notify_admin"
+	default:
+		return "ignore"
+	}
+}
+
+const (
+	RequestTypeFriend = "friend"
+	RequestTypeGroup  = "group"
+
+	SubTypeAdd    = "add"
+	SubTypeInvite = "invite"
+)
+
+// Request 好友请求和加群请求的统一封装
+type Request struct {
+	RequestType string
+	SubType     string
+	GroupId     int64
+	UserId      int64
+	Comment     string
+	Flag        string
+}
+
+// Answer 加群问题的回答，comment 格式为 "问题：xxx\n答案：yyy"，没有答案时返回整个 comment
+func (r *Request) Answer() string {
+	if i := strings.LastIndex(r.Comment, "答案："); i >= 0 {
+		return strings.TrimSpace(r.Comment[i+len("答案："):])
+	}
+	return strings.TrimSpace(r.Comment)
+}
+
+// Rule 规则，所有非空条件都满足时匹配
+type Rule struct {
+	Name        string
+	RequestType string   // friend 或 group，空表示全部
+	SubTypes    []string // add 或 invite，空表示全部
+	GroupIds    []int64  // 空表示全部群
+	UserIds     []int64  // 空表示全部用户
+	Keywords    []string // Comment 包含任意一个关键词
+	Pattern     *regexp.Regexp
+	MatchAnswer bool // Keywords 和 Pattern 只匹配加群问题的回答
+	Outcome     Outcome
+	Reason      string // 拒绝理由，同意好友请求时作为备注
+}
+
+func (rule *Rule) Match(req *Request) bool {
+	if rule.RequestType != "" && rule.RequestType != req.RequestType {
+		return false
+	}
+	if len(rule.SubTypes) > 0 && !containsString(rule.SubTypes, req.SubType) {
+		return false
+	}
+	if len(rule.GroupIds) > 0 && !containsInt64(rule.GroupIds, req.GroupId) {
+		return false
+	}
+	if len(rule.UserIds) > 0 && !containsInt64(rule.UserIds, req.UserId) {
+		return false
+	}
+	text := req.Comment
+	if rule.MatchAnswer {
+		text = req.Answer()
+	}
+	if len(rule.Keywords) > 0 {
+		matched := false
+		for _, keyword := range rule.Keywords {
+			if strings.Contains(text, keyword) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if rule.Pattern != nil && !rule.Pattern.MatchString(text) {
+		return false
+	}
+	return true
+}
+
+const (
+	DefaultPendingTTL = 24 * time.Hour // 等待中的请求默认保留时间
+	DefaultMaxPending = 1000           // 默认最多保留的等待中请求数量
+)
+
+// Policy 按顺序匹配规则，黑名单用户直接拒绝，没有匹配的规则时使用 Default
+type Policy struct {
+	Rules     []*Rule
+	Blacklist map[int64]bool
+	Default   Outcome
+	// Notify OutcomeNotifyAdmin 时调用，为 nil 时私聊通知所有 pbbot.SuperUsers
+	Notify func(bot *pbbot.Bot, req *Request)
+	// PendingTTL 等待中的请求超过这个时间后删除，小于等于 0 表示不过期
+	PendingTTL time.Duration
+	// MaxPending 等待中的请求超过这个数量时删除最早的，小于等于 0 表示不限制
+	MaxPending int
+
+	mu      sync.Mutex
+	seq     uint64
+	pending map[string]*pendingRequest
+}
+
+type pendingRequest struct {
+	req     *Request
+	seq     uint64
+	addedAt time.Time
+}
+
+func NewPolicy(defaultOutcome Outcome, rules ...*Rule) *Policy {
+	return &Policy{
+		Rules:      rules,
+		Blacklist:  make(map[int64]bool),
+		Default:    defaultOutcome,
+		PendingTTL: DefaultPendingTTL,
+		MaxPending: DefaultMaxPending,
+		pending:    make(map[string]*pendingRequest),
+	}
+}
+
+// Evaluate 返回匹配的规则和结果，没有匹配的规则时 rule 为 nil
+func (p *Policy) Evaluate(req *Request) (*Rule, Outcome) {
+	if p.Blacklist[req.UserId] {
+		return nil, OutcomeReject
+	}
+	for _, rule := range p.Rules {
+		if rule.Match(req) {
+			return rule, rule.Outcome
+		}
+	}
+	return nil, p.Default
+}
+
+// Install 替换 pbbot.HandleFriendRequest 和 pbbot.HandleGroupRequest
+func (p *Policy) Install() {
+	pbbot.HandleFriendRequest = p.HandleFriendRequest
+	pbbot.HandleGroupRequest = p.HandleGroupRequest
+}
+
+func (p *Policy) HandleFriendRequest(bot *pbbot.Bot, event *onebot.FriendRequestEvent) {
+	p.Handle(bot, &Request{
+		RequestType: RequestTypeFriend,
+		UserId:      event.UserId,
+		Comment:     event.Comment,
+		Flag:        event.Flag,
+	})
+}
+
+func (p *Policy) HandleGroupRequest(bot *pbbot.Bot, event *onebot.GroupRequestEvent) {
+	p.Handle(bot, &Request{
+		RequestType: RequestTypeGroup,
+		SubType:     event.SubType,
+		GroupId:     event.GroupId,
+		UserId:      event.UserId,
+		Comment:     event.Comment,
+		Flag:        event.Flag,
+	})
+}
+
+func (p *Policy) Handle(bot *pbbot.Bot, req *Request) {
+	rule, outcome := p.Evaluate(req)
+	ruleName, reason := "default", ""
+	if rule != nil {
+		ruleName, reason = rule.Name, rule.Reason
+	}
+	bot.Logger.Log(pbbot.LevelInfo, "request policy", pbbot.F("request_type", req.RequestType), pbbot.F("sub_type", req.SubType),
+		pbbot.F(pbbot.FieldGroupId, req.GroupId), pbbot.F(pbbot.FieldUserId, req.UserId), pbbot.F("rule", ruleName), pbbot.F("outcome", outcome.String()))
+	switch outcome {
+	case OutcomeApprove, OutcomeReject:
+		if err := p.apply(bot, req, outcome == OutcomeApprove, reason); err != nil {
+			bot.Logger.Log(pbbot.LevelError, "failed to handle request", pbbot.Err(err))
+		}
+	case OutcomeNotifyAdmin:
+		p.addPending(req)
+		if p.Notify != nil {
+			p.Notify(bot, req)
+		} else {
+			notifySuperUsers(bot, req)
+		}
+	}
+}
+
+func (p *Policy) addPending(req *Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	p.pruneLocked(now)
+	delete(p.pending, req.Flag)
+	if p.MaxPending > 0 && len(p.pending) >= p.MaxPending {
+		entries := p.sortedLocked()
+		for _, entry := range entries[:len(entries)-p.MaxPending+1] {
+			delete(p.pending, entry.req.Flag)
+		}
+	}
+	p.seq++
+	p.pending[req.Flag] = &pendingRequest{req: req, seq: p.seq, addedAt: now}
+}
+
+// pruneLocked 删除过期的请求
+func (p *Policy) pruneLocked(now time.Time) {
+	if p.PendingTTL <= 0 {
+		return
+	}
+	for flag, entry := range p.pending {
+		if now.Sub(entry.addedAt) >= p.PendingTTL {
+			delete(p.pending, flag)
+		}
+	}
+}
+
+// sortedLocked 按加入时间排序
+func (p *Policy) sortedLocked() []*pendingRequest {
+	entries := make([]*pendingRequest, 0, len(p.pending))
+	for _, entry := range p.pending {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+	return entries
+}
+
+// Pending 等待管理员处理的请求，按加入时间排序
+func (p *Policy) Pending() []*Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pruneLocked(time.Now())
+	entries := p.sortedLocked()
+	result := make([]*Request, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.req)
+	}
+	return result
+}
+
+// Resolve 管理员处理等待中的请求，调用API失败时请求保留在等待列表中，可以重试
+func (p *Policy) Resolve(bot *pbbot.Bot, flag string, approve bool, reason string) error {
+	p.mu.Lock()
+	p.pruneLocked(time.Now())
+	entry, ok := p.pending[flag]
+	p.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("request %s not found", flag)
+	}
+	if err := p.apply(bot, entry.req, approve, reason); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	if p.pending[flag] == entry {
+		delete(p.pending, flag)
+	}
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Policy) apply(bot *pbbot.Bot, req *Request, approve bool, reason string) error {
+	if req.RequestType == RequestTypeFriend {
+		remark := ""
+		if approve {
+			remark = reason
+		}
+		_, err := bot.SetFriendAddRequest(req.Flag, approve, remark)
+		return err
+	}
+	if approve {
+		reason = ""
+	}
+	_, err := bot.SetGroupAddRequestWithSubType(req.Flag, req.SubType, approve, reason)
+	return err
+}
+
+func notifySuperUsers(bot *pbbot.Bot, req *Request) {
+	text := fmt.Sprintf("收到%s请求\n类型：%s\n群：%d\n用户：%d\n附言：%s\nflag：%s",
+		req.RequestType, req.SubType, req.GroupId, req.UserId, req.Comment, req.Flag)
+	for userId := range pbbot.SuperUsers {
+		if _, err := bot.SendPrivateMessage(userId, pbbot.NewMsg().Text(text), false); err != nil {
+			bot.Logger.Log(pbbot.LevelError, "failed to notify super user", pbbot.F(pbbot.FieldUserId, userId), pbbot.Err(err))
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt64(list []int64, n int64) bool {
+	for _, item := range list {
+		if item == n {
+			return true
+		}
+	}
+	return false
+}
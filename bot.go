@@ -1,76 +1,181 @@
 package pbbot
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
 	"github.com/ProtobufBot/go-pbbot/util"
 	"github.com/fanliao/go-promise"
 	"github.com/golang/protobuf/proto"
 	"github.com/gorilla/websocket"
-	log "github.com/sirupsen/logrus"
 )
 
 var Bots = make(map[int64]*Bot)
 
+// botsLock 保护 Bots 的并发读写
+var botsLock sync.RWMutex
+
+// ApiTimeout 调用API等待响应的超时时间
+var ApiTimeout = 120 * time.Second
+
+var ErrTimeout = errors.New("timeout waiting for resp frame")
+
+var ErrDisconnected = errors.New("bot disconnected")
+
 type Bot struct {
 	BotId         int64
 	Session       *SafeWebSocket
 	WaitingFrames map[string]*promise.Promise
+	Cache         *Cache
+	RateLimiter   *RateLimiter
+	// Logger 带有 bot_id 字段的日志
+	Logger Logger
+	// RemoteAddr 连接的远程地址
+	RemoteAddr string
+	// ConnectedAt 连接时间
+	ConnectedAt time.Time
+
+	waitingLock *sync.Mutex
+	ctx         context.Context
 }
 
 func NewBot(botId int64, conn *websocket.Conn) *Bot {
+	return NewBotWithLogger(botId, conn, DefaultLogger)
+}
+
+// NewBotWithLogger 使用指定的日志创建机器人，logger 为 nil 时使用 DefaultLogger
+func NewBotWithLogger(botId int64, conn *websocket.Conn, logger Logger) *Bot {
+	if logger == nil {
+		logger = DefaultLogger
+	}
+	logger = logger.With(F(FieldBotId, botId))
 	messageHandler := func(messageType int, data []byte) {
 		var frame onebot.Frame
 		if messageType == websocket.BinaryMessage {
 			err := proto.Unmarshal(data, &frame)
 			if err != nil {
-				log.Errorf("failed to unmarshal websocket binary message, err: %+v", err)
+				logger.Log(LevelError, "failed to unmarshal websocket binary message", Err(err))
 				return
 			}
 		} else if messageType == websocket.TextMessage {
 			err := json.Unmarshal(data, &frame)
 			if err != nil {
-				log.Errorf("failed to unmarshal websocket text message, err: %+v", err)
+				logger.Log(LevelError, "failed to unmarshal websocket text message", Err(err))
 				return
 			}
 		} else {
-			log.Errorf("invalid websocket messageType: %+v", messageType)
+			logger.Log(LevelError, "invalid websocket message type", F("message_type", messageType))
 			return
 		}
+		if logger.Enabled(LevelTrace) {
+			logger.Log(LevelTrace, "recv frame", F(FieldFrameType, frame.FrameType.String()), F(FieldEcho, frame.Echo))
+		}
 
-		bot, ok := Bots[botId]
+		bot, ok := GetBot(botId)
 		if !ok {
 			_ = conn.Close()
 			return
 		}
 		util.SafeGo(func() {
-			bot.handleFrame(&frame)
+			interceptFrame(context.Background(), bot, &frame, func(ctx context.Context) {
+				bot.WithContext(ctx).handleFrame(&frame)
+			})
 		})
 	}
+	var bot *Bot
 	closeHandler := func(code int, message string) {
-		HandleDisconnect(Bots[botId])
-		delete(Bots, botId)
+		botsLock.Lock()
+		if Bots[botId] == bot {
+			delete(Bots, botId)
+		}
+		botsLock.Unlock()
+		HandleDisconnect(bot)
 	}
-	safeWs := NewSafeWebSocket(conn, messageHandler, closeHandler)
-	bot := &Bot{
+	safeWs := newSafeWebSocket(conn, messageHandler, closeHandler, logger)
+	bot = &Bot{
 		BotId:         botId,
 		Session:       safeWs,
 		WaitingFrames: make(map[string]*promise.Promise),
+		Logger:        logger,
+		RemoteAddr:    conn.RemoteAddr().String(),
+		ConnectedAt:   time.Now(),
+		waitingLock:   &sync.Mutex{},
 	}
+	bot.Cache = NewCache(bot)
+	if DefaultRateLimitConfig != nil {
+		bot.RateLimiter = NewRateLimiter(*DefaultRateLimitConfig)
+	}
+	botsLock.Lock()
 	Bots[botId] = bot
+	botsLock.Unlock()
 	HandleConnect(bot)
+	if EnableCache {
+		util.SafeGo(func() {
+			if err := bot.Cache.Refresh(); err != nil {
+				logger.Log(LevelError, "failed to warm cache", Err(err))
+			}
+		})
+	}
 	return bot
 }
 
+// WithContext 返回使用 ctx 调用API的机器人副本，与原机器人共享连接和状态
+// 事件处理函数收到的机器人已经带有事件的 ctx，API调用会作为事件处理的子调用
+func (bot *Bot) WithContext(ctx context.Context) *Bot {
+	b := *bot
+	b.ctx = ctx
+	return &b
+}
+
+// Context 调用API时使用的 ctx，没有设置时为 context.Background()
+func (bot *Bot) Context() context.Context {
+	if bot.ctx == nil {
+		return context.Background()
+	}
+	return bot.ctx
+}
+
+// ListBots 获取所有已连接的机器人
+func ListBots() []*Bot {
+	botsLock.RLock()
+	defer botsLock.RUnlock()
+	bots := make([]*Bot, 0, len(Bots))
+	for _, bot := range Bots {
+		bots = append(bots, bot)
+	}
+	return bots
+}
+
+// GetBot 获取已连接的机器人
+func GetBot(botId int64) (*Bot, bool) {
+	botsLock.RLock()
+	defer botsLock.RUnlock()
+	bot, ok := Bots[botId]
+	return bot, ok
+}
+
 func (bot *Bot) handleFrame(frame *onebot.Frame) {
+	if EnableCache {
+		bot.Cache.update(frame)
+	}
+	if MessageHistory != nil {
+		bot.recordHistory(frame)
+	}
 	if event := frame.GetPrivateMessageEvent(); event != nil {
-		HandlePrivateMessage(bot, event)
+		dispatchMessage(NewPrivateMessageContext(bot, event), func() {
+			HandlePrivateMessage(bot, event)
+		})
 		return
 	}
 	if event := frame.GetGroupMessageEvent(); event != nil {
-		HandleGroupMessage(bot, event)
+		dispatchMessage(NewGroupMessageContext(bot, event), func() {
+			HandleGroupMessage(bot, event)
+		})
 		return
 	}
 	if event := frame.GetGroupUploadNoticeEvent(); event != nil {
@@ -115,44 +220,120 @@ func (bot *Bot) handleFrame(frame *onebot.Frame) {
 	}
 
 	if frame.FrameType < 300 {
-		log.Errorf("unknown frame type: %+v", frame.FrameType)
+		bot.Logger.Log(LevelError, "unknown frame type", F(FieldFrameType, frame.FrameType.String()))
 		return
 	}
+	bot.waitingLock.Lock()
 	p, ok := bot.WaitingFrames[frame.Echo]
+	bot.waitingLock.Unlock()
 	if !ok {
-		log.Errorf("failed to find waiting frame")
+		bot.Logger.Log(LevelError, "failed to find waiting frame", F(FieldFrameType, frame.FrameType.String()), F(FieldEcho, frame.Echo))
 		return
 	}
 	if err := p.Resolve(frame); err != nil {
-		log.Errorf("failed to resolve waiting frame promise")
+		bot.Logger.Log(LevelError, "failed to resolve waiting frame promise", F(FieldFrameType, frame.FrameType.String()), F(FieldEcho, frame.Echo), Err(err))
 		return
 	}
 }
 
 func (bot *Bot) sendFrameAndWait(frame *onebot.Frame) (*onebot.Frame, error) {
+	return bot.sendFrameAndWaitContext(bot.Context(), frame)
+}
+
+// sendFrameAndWaitContext 发送请求并等待响应，ctx 取消或超过 ApiTimeout 时返回错误
+func (bot *Bot) sendFrameAndWaitContext(ctx context.Context, frame *onebot.Frame) (*onebot.Frame, error) {
 	frame.BotId = bot.BotId
 	frame.Echo = util.GenerateIdStr()
 	frame.Ok = true
+	return interceptApi(ctx, bot, frame, func(ctx context.Context) (*onebot.Frame, error) {
+		return bot.roundTrip(ctx, frame)
+	})
+}
+
+// Disconnect 强制断开连接，断开后从 Bots 中删除并调用 HandleDisconnect
+func (bot *Bot) Disconnect() error {
+	return bot.Session.Conn.Close()
+}
+
+// PendingCalls 正在等待响应的API调用数量
+func (bot *Bot) PendingCalls() int {
+	bot.waitingLock.Lock()
+	defer bot.waitingLock.Unlock()
+	return len(bot.WaitingFrames)
+}
+
+func (bot *Bot) roundTrip(ctx context.Context, frame *onebot.Frame) (*onebot.Frame, error) {
+	if bot.RateLimiter != nil {
+		if groupId, userId, priority, limited := rateLimitKey(frame); limited {
+			if p, ok := ctx.Value(priorityContextKey{}).(Priority); ok {
+				priority = p
+			}
+			if err := bot.RateLimiter.Wait(ctx, groupId, userId, priority); err != nil {
+				return nil, err
+			}
+		}
+	}
 	data, err := proto.Marshal(frame)
 	if err != nil {
 		return nil, err
 	}
-	bot.Session.Send(websocket.BinaryMessage, data)
 	p := promise.NewPromise()
+	bot.waitingLock.Lock()
 	bot.WaitingFrames[frame.Echo] = p
-	defer delete(bot.WaitingFrames, frame.Echo)
-	resp, err, timeout := p.GetOrTimeout(120000)
-	if err != nil || timeout {
+	bot.waitingLock.Unlock()
+	defer func() {
+		bot.waitingLock.Lock()
+		delete(bot.WaitingFrames, frame.Echo)
+		bot.waitingLock.Unlock()
+	}()
+	if bot.Logger.Enabled(LevelTrace) {
+		bot.Logger.Log(LevelTrace, "send frame", F(FieldFrameType, frame.FrameType.String()), F(FieldEcho, frame.Echo))
+	}
+	if err := bot.Session.sendContext(ctx, websocket.BinaryMessage, data); err != nil {
 		return nil, err
 	}
-	respFrame, ok := resp.(*onebot.Frame)
+
+	timer := time.NewTimer(ApiTimeout)
+	defer timer.Stop()
+	var result *promise.PromiseResult
+	select {
+	case result = <-p.GetChan():
+	case <-timer.C:
+		bot.Logger.Log(LevelWarn, "timeout waiting for resp frame", F(FieldFrameType, frame.FrameType.String()), F(FieldEcho, frame.Echo))
+		return nil, ErrTimeout
+	case <-bot.Session.Done():
+		return nil, ErrDisconnected
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.Typ != promise.RESULT_SUCCESS {
+		return nil, fmt.Errorf("failed to wait resp frame, %+v", result.Result)
+	}
+	respFrame, ok := result.Result.(*onebot.Frame)
 	if !ok {
 		return nil, errors.New("failed to convert promise result to resp frame")
 	}
 	return respFrame, nil
 }
 
-func (bot *Bot) SendPrivateMessage(userId int64, msg *Msg, autoEscape bool) (*onebot.SendPrivateMsgResp, error) {
+// SendPrivateMessage 发送私聊消息，可以通过 WithSplit 拆分发送，拆分时返回最后一条消息的响应
+func (bot *Bot) SendPrivateMessage(userId int64, msg *Msg, autoEscape bool, opts ...SendOption) (*onebot.SendPrivateMsgResp, error) {
+	var resp *onebot.SendPrivateMsgResp
+	err := bot.sendWithOptions(bot.Context(), msg, opts, func(part *Msg) (int32, error) {
+		var err error
+		resp, err = bot.sendPrivateMessage(userId, part, autoEscape)
+		return resp.GetMessageId(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (bot *Bot) sendPrivateMessage(userId int64, msg *Msg, autoEscape bool) (*onebot.SendPrivateMsgResp, error) {
+	if err := msg.Err(); err != nil {
+		return nil, err
+	}
 	if resp, err := bot.sendFrameAndWait(&onebot.Frame{
 		FrameType: onebot.Frame_TSendPrivateMsgReq,
 		Data: &onebot.Frame_SendPrivateMsgReq{
@@ -165,11 +346,29 @@ func (bot *Bot) SendPrivateMessage(userId int64, msg *Msg, autoEscape bool) (*on
 	}); err != nil {
 		return nil, err
 	} else {
+		bot.recordSent(User(userId), msg, resp.GetSendPrivateMsgResp().GetMessageId())
 		return resp.GetSendPrivateMsgResp(), nil
 	}
 }
 
-func (bot *Bot) SendGroupMessage(groupId int64, msg *Msg, autoEscape bool) (*onebot.SendGroupMsgResp, error) {
+// SendGroupMessage 发送群消息，可以通过 WithSplit 拆分发送，拆分时返回最后一条消息的响应
+func (bot *Bot) SendGroupMessage(groupId int64, msg *Msg, autoEscape bool, opts ...SendOption) (*onebot.SendGroupMsgResp, error) {
+	var resp *onebot.SendGroupMsgResp
+	err := bot.sendWithOptions(bot.Context(), msg, opts, func(part *Msg) (int32, error) {
+		var err error
+		resp, err = bot.sendGroupMessage(groupId, part, autoEscape)
+		return resp.GetMessageId(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (bot *Bot) sendGroupMessage(groupId int64, msg *Msg, autoEscape bool) (*onebot.SendGroupMsgResp, error) {
+	if err := msg.Err(); err != nil {
+		return nil, err
+	}
 	if resp, err := bot.sendFrameAndWait(&onebot.Frame{
 		FrameType: onebot.Frame_TSendGroupMsgReq,
 		Data: &onebot.Frame_SendGroupMsgReq{
@@ -182,6 +381,7 @@ func (bot *Bot) SendGroupMessage(groupId int64, msg *Msg, autoEscape bool) (*one
 	}); err != nil {
 		return nil, err
 	} else {
+		bot.recordSent(Group(groupId), msg, resp.GetSendGroupMsgResp().GetMessageId())
 		return resp.GetSendGroupMsgResp(), nil
 	}
 }
@@ -216,6 +416,22 @@ func (bot *Bot) GetMsg(messageId int32) (*onebot.GetMsgResp, error) {
 	}
 }
 
+// GetForwardMsg 获取合并转发消息，目前协议中 GetForwardMsgResp 没有定义字段，节点内容需要通过 ParseForwardMsg 从消息段中解析
+func (bot *Bot) GetForwardMsg(id string) (*onebot.GetForwardMsgResp, error) {
+	if resp, err := bot.sendFrameAndWait(&onebot.Frame{
+		FrameType: onebot.Frame_TGetForwardMsgReq,
+		Data: &onebot.Frame_GetForwardMsgReq{
+			GetForwardMsgReq: &onebot.GetForwardMsgReq{
+				Id: id,
+			},
+		},
+	}); err != nil {
+		return nil, err
+	} else {
+		return resp.GetGetForwardMsgResp(), nil
+	}
+}
+
 func (bot *Bot) SetGroupKick(groupId int64, userId int64, rejectAddRequest bool) (*onebot.SetGroupKickResp, error) {
 	if resp, err := bot.sendFrameAndWait(&onebot.Frame{
 		FrameType: onebot.Frame_TSetGroupKickReq,
@@ -351,6 +567,26 @@ func (bot *Bot) SetGroupAddRequest(flag string, approve bool, reason string) (*o
 	}
 }
 
+// SetGroupAddRequestWithSubType 处理加群请求或邀请，subType 为 add 或 invite
+func (bot *Bot) SetGroupAddRequestWithSubType(flag string, subType string, approve bool, reason string) (*onebot.SetGroupAddRequestResp, error) {
+	if resp, err := bot.sendFrameAndWait(&onebot.Frame{
+		FrameType: onebot.Frame_TSetGroupAddRequestReq,
+		Data: &onebot.Frame_SetGroupAddRequestReq{
+			SetGroupAddRequestReq: &onebot.SetGroupAddRequestReq{
+				Flag:    flag,
+				SubType: subType,
+				Type:    subType,
+				Approve: approve,
+				Reason:  reason,
+			},
+		},
+	}); err != nil {
+		return nil, err
+	} else {
+		return resp.GetSetGroupAddRequestResp(), nil
+	}
+}
+
 func (bot *Bot) GetLoginInfo() (*onebot.GetLoginInfoResp, error) {
 	if resp, err := bot.sendFrameAndWait(&onebot.Frame{
 		FrameType: onebot.Frame_TGetLoginInfoReq,
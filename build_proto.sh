@@ -3,3 +3,14 @@
 mkdir -p proto_gen/onebot
 
 protoc -I onebot_idl --gofast_out=proto_gen/onebot onebot_idl/*.proto
+
+# onebot_idl 是上游子模块，其中的 proto 都生成到 proto_gen/onebot，
+# 所以 OneBot 服务定义放在 grpcserver/onebot_service.proto，引用 onebot_idl 中的消息，生成的代码放在 grpcserver 下
+# 需要 protoc-gen-go-grpc: go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.2.0
+ONEBOT_PKG=github.com/ProtobufBot/go-pbbot/proto_gen/onebot
+ONEBOT_MAP=Monebot_api.proto=$ONEBOT_PKG,Monebot_base.proto=$ONEBOT_PKG,Monebot_event.proto=$ONEBOT_PKG,Monebot_frame.proto=$ONEBOT_PKG
+
+protoc -I onebot_idl -I grpcserver \
+  --gofast_out=paths=source_relative,$ONEBOT_MAP:grpcserver \
+  --go-grpc_out=paths=source_relative,$ONEBOT_MAP:grpcserver \
+  grpcserver/onebot_service.proto
@@ -0,0 +1,316 @@
+package pbbot
+
+import (
+	"sync"
+
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/ProtobufBot/go-pbbot/util"
+)
+
+// EnableCache 机器人连接后是否缓存群、群成员和好友信息
+var EnableCache = true
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Cache 群、群成员和好友信息的本地缓存，连接时加载，之后根据事件更新，返回的对象不会再被修改
+//
+// 协议没有群名片变更的通知，群名片只在收到该成员的群消息时根据 GroupMessageEvent.Sender 更新
+type Cache struct {
+	bot *Bot
+
+	mu      sync.RWMutex
+	groups  map[int64]*onebot.GetGroupListResp_Group
+	members map[int64]map[int64]*onebot.GetGroupMemberListResp_GroupMember
+	friends map[int64]*onebot.GetFriendListResp_Friend
+}
+
+func NewCache(bot *Bot) *Cache {
+	return &Cache{
+		bot:     bot,
+		groups:  make(map[int64]*onebot.GetGroupListResp_Group),
+		members: make(map[int64]map[int64]*onebot.GetGroupMemberListResp_GroupMember),
+		friends: make(map[int64]*onebot.GetFriendListResp_Friend),
+	}
+}
+
+// Refresh 重新加载好友列表、群列表和所有群的成员列表，单个群的成员列表加载失败时记录日志并继续加载其他群
+func (c *Cache) Refresh() error {
+	if err := c.RefreshFriends(); err != nil {
+		return err
+	}
+	resp, err := c.bot.GetGroupList()
+	if err != nil {
+		return err
+	}
+	groups := make(map[int64]*onebot.GetGroupListResp_Group)
+	groupIds := make([]int64, 0, len(resp.GetGroup()))
+	for _, group := range resp.GetGroup() {
+		groups[group.GroupId] = group
+		groupIds = append(groupIds, group.GroupId)
+	}
+	c.mu.Lock()
+	c.groups = groups
+	for groupId := range c.members {
+		if _, ok := groups[groupId]; !ok {
+			delete(c.members, groupId)
+		}
+	}
+	c.mu.Unlock()
+	// groups 交给 c.groups 后会被事件更新，这里只遍历群号
+	for _, groupId := range groupIds {
+		if err := c.RefreshMembers(groupId); err != nil {
+			c.bot.Logger.Log(LevelError, "failed to refresh group members", F(FieldGroupId, groupId), Err(err))
+		}
+	}
+	return nil
+}
+
+// RefreshFriends 重新加载好友列表
+func (c *Cache) RefreshFriends() error {
+	resp, err := c.bot.GetFriendList()
+	if err != nil {
+		return err
+	}
+	friends := make(map[int64]*onebot.GetFriendListResp_Friend)
+	for _, friend := range resp.GetFriend() {
+		friends[friend.UserId] = friend
+	}
+	c.mu.Lock()
+	c.friends = friends
+	c.mu.Unlock()
+	return nil
+}
+
+// RefreshGroup 重新加载群信息和群成员列表
+func (c *Cache) RefreshGroup(groupId int64) error {
+	resp, err := c.bot.GetGroupInfo(groupId, true)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.groups[groupId] = &onebot.GetGroupListResp_Group{
+		GroupId:        resp.GetGroupId(),
+		GroupName:      resp.GetGroupName(),
+		MemberCount:    resp.GetMemberCount(),
+		MaxMemberCount: resp.GetMaxMemberCount(),
+	}
+	c.mu.Unlock()
+	return c.RefreshMembers(groupId)
+}
+
+// RefreshMembers 重新加载群成员列表
+func (c *Cache) RefreshMembers(groupId int64) error {
+	resp, err := c.bot.GetGroupMemberList(groupId)
+	if err != nil {
+		return err
+	}
+	members := make(map[int64]*onebot.GetGroupMemberListResp_GroupMember)
+	for _, member := range resp.GetGroupMember() {
+		members[member.UserId] = member
+	}
+	c.mu.Lock()
+	c.members[groupId] = members
+	c.mu.Unlock()
+	return nil
+}
+
+// RefreshMember 重新加载单个群成员
+func (c *Cache) RefreshMember(groupId int64, userId int64) error {
+	resp, err := c.bot.GetGroupMemberInfo(groupId, userId, true)
+	if err != nil {
+		return err
+	}
+	c.setMember(&onebot.GetGroupMemberListResp_GroupMember{
+		GroupId:         groupId,
+		UserId:          userId,
+		Nickname:        resp.GetNickname(),
+		Card:            resp.GetCard(),
+		Sex:             resp.GetSex(),
+		Age:             resp.GetAge(),
+		Area:            resp.GetArea(),
+		JoinTime:        resp.GetJoinTime(),
+		LastSentTime:    resp.GetLastSentTime(),
+		Level:           resp.GetLevel(),
+		Role:            resp.GetRole(),
+		Unfriendly:      resp.GetUnfriendly(),
+		Title:           resp.GetTitle(),
+		TitleExpireTime: resp.GetTitleExpireTime(),
+		CardChangeable:  resp.GetCardChangeable(),
+	})
+	return nil
+}
+
+func (c *Cache) Group(groupId int64) (*onebot.GetGroupListResp_Group, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	group, ok := c.groups[groupId]
+	return group, ok
+}
+
+func (c *Cache) Groups() []*onebot.GetGroupListResp_Group {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	groups := make([]*onebot.GetGroupListResp_Group, 0, len(c.groups))
+	for _, group := range c.groups {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func (c *Cache) Member(groupId int64, userId int64) (*onebot.GetGroupMemberListResp_GroupMember, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	member, ok := c.members[groupId][userId]
+	return member, ok
+}
+
+func (c *Cache) Members(groupId int64) []*onebot.GetGroupMemberListResp_GroupMember {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	members := make([]*onebot.GetGroupMemberListResp_GroupMember, 0, len(c.members[groupId]))
+	for _, member := range c.members[groupId] {
+		members = append(members, member)
+	}
+	return members
+}
+
+func (c *Cache) Friend(userId int64) (*onebot.GetFriendListResp_Friend, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	friend, ok := c.friends[userId]
+	return friend, ok
+}
+
+func (c *Cache) IsFriend(userId int64) bool {
+	_, ok := c.Friend(userId)
+	return ok
+}
+
+func (c *Cache) InGroup(groupId int64, userId int64) bool {
+	_, ok := c.Member(groupId, userId)
+	return ok
+}
+
+// IsAdmin 是否是群主或管理员
+func (c *Cache) IsAdmin(groupId int64, userId int64) bool {
+	member, ok := c.Member(groupId, userId)
+	return ok && (member.Role == RoleOwner || member.Role == RoleAdmin)
+}
+
+func (c *Cache) IsOwner(groupId int64, userId int64) bool {
+	member, ok := c.Member(groupId, userId)
+	return ok && member.Role == RoleOwner
+}
+
+// MemberCard 群名片，没有群名片时返回昵称
+func (c *Cache) MemberCard(groupId int64, userId int64) string {
+	member, ok := c.Member(groupId, userId)
+	if !ok {
+		return ""
+	}
+	if member.Card != "" {
+		return member.Card
+	}
+	return member.Nickname
+}
+
+func (c *Cache) setMember(member *onebot.GetGroupMemberListResp_GroupMember) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.members[member.GroupId] == nil {
+		c.members[member.GroupId] = make(map[int64]*onebot.GetGroupMemberListResp_GroupMember)
+	}
+	c.members[member.GroupId][member.UserId] = member
+}
+
+func (c *Cache) removeMember(groupId int64, userId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.members[groupId], userId)
+	if group, ok := c.groups[groupId]; ok && group.MemberCount > 0 {
+		updated := *group
+		updated.MemberCount--
+		c.groups[groupId] = &updated
+	}
+}
+
+func (c *Cache) removeGroup(groupId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.groups, groupId)
+	delete(c.members, groupId)
+}
+
+// update 根据事件更新缓存，需要调用API的更新在新的协程中进行
+func (c *Cache) update(frame *onebot.Frame) {
+	refresh := func(fn func() error) {
+		util.SafeGo(func() {
+			if err := fn(); err != nil {
+				c.bot.Logger.Log(LevelError, "failed to refresh cache", Err(err))
+			}
+		})
+	}
+	if event := frame.GetGroupMessageEvent(); event != nil && event.Sender != nil {
+		c.mu.Lock()
+		if member, ok := c.members[event.GroupId][event.UserId]; ok {
+			updated := *member
+			updated.Card = event.Sender.Card
+			updated.Nickname = event.Sender.Nickname
+			if event.Sender.Role != "" {
+				updated.Role = event.Sender.Role
+			}
+			updated.LastSentTime = event.Time
+			c.members[event.GroupId][event.UserId] = &updated
+		}
+		c.mu.Unlock()
+		return
+	}
+	if event := frame.GetGroupIncreaseNoticeEvent(); event != nil {
+		if event.UserId == c.bot.BotId {
+			refresh(func() error { return c.RefreshGroup(event.GroupId) })
+			return
+		}
+		c.mu.Lock()
+		if group, ok := c.groups[event.GroupId]; ok {
+			updated := *group
+			updated.MemberCount++
+			c.groups[event.GroupId] = &updated
+		}
+		c.mu.Unlock()
+		refresh(func() error { return c.RefreshMember(event.GroupId, event.UserId) })
+		return
+	}
+	if event := frame.GetGroupDecreaseNoticeEvent(); event != nil {
+		if event.UserId == c.bot.BotId || event.SubType == "kick_me" {
+			c.removeGroup(event.GroupId)
+			return
+		}
+		c.removeMember(event.GroupId, event.UserId)
+		return
+	}
+	if event := frame.GetGroupAdminNoticeEvent(); event != nil {
+		c.mu.Lock()
+		if member, ok := c.members[event.GroupId][event.UserId]; ok {
+			updated := *member
+			if event.SubType == "set" {
+				updated.Role = RoleAdmin
+			} else if event.SubType == "unset" {
+				updated.Role = RoleMember
+			}
+			c.members[event.GroupId][event.UserId] = &updated
+		}
+		c.mu.Unlock()
+		return
+	}
+	if event := frame.GetFriendAddNoticeEvent(); event != nil {
+		c.mu.Lock()
+		c.friends[event.UserId] = &onebot.GetFriendListResp_Friend{UserId: event.UserId}
+		c.mu.Unlock()
+		refresh(c.RefreshFriends)
+		return
+	}
+}
@@ -0,0 +1,91 @@
+package pbbot
+
+import (
+	"strings"
+	"sync"
+)
+
+// CommandPrefixes 命令前缀，消息纯文本以前缀开头时才会匹配命令
+var CommandPrefixes = []string{"/"}
+
+// Command 命令，例如 "/ban 123 60" 的 Name 为 ban，参数为 ["123", "60"]
+type Command struct {
+	Name       string
+	Aliases    []string
+	Permission Permission
+	GroupOnly  bool
+	Handler    func(ctx *MessageContext, args []string)
+	// Enabled 不为 nil 且返回 false 时不匹配该命令，例如插件在当前群被禁用
+	Enabled func(ctx *MessageContext) bool
+}
+
+var (
+	commandsLock sync.RWMutex
+	commands     = make(map[string]*Command)
+)
+
+// RegisterCommand 注册命令，同名命令会被覆盖
+func RegisterCommand(command *Command) {
+	commandsLock.Lock()
+	defer commandsLock.Unlock()
+	commands[command.Name] = command
+	for _, alias := range command.Aliases {
+		commands[alias] = command
+	}
+}
+
+// UnregisterCommand 取消注册命令及其别名
+func UnregisterCommand(name string) {
+	commandsLock.Lock()
+	defer commandsLock.Unlock()
+	command, ok := commands[name]
+	if !ok {
+		return
+	}
+	for key, c := range commands {
+		if c == command {
+			delete(commands, key)
+		}
+	}
+}
+
+// ParseCommand 解析命令名和参数，不是命令时返回 false
+func ParseCommand(text string) (string, []string, bool) {
+	text = strings.TrimSpace(text)
+	for _, prefix := range CommandPrefixes {
+		if !strings.HasPrefix(text, prefix) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(text, prefix))
+		if len(fields) == 0 {
+			return "", nil, false
+		}
+		return fields[0], fields[1:], true
+	}
+	return "", nil, false
+}
+
+// dispatchCommand 匹配并执行命令，匹配到命令时返回 true
+func dispatchCommand(ctx *MessageContext) bool {
+	name, args, ok := ParseCommand(ctx.Msg.PlainText())
+	if !ok {
+		return false
+	}
+	commandsLock.RLock()
+	command, ok := commands[name]
+	commandsLock.RUnlock()
+	if !ok {
+		return false
+	}
+	if command.Enabled != nil && !command.Enabled(ctx) {
+		return false
+	}
+	if command.GroupOnly && !ctx.IsGroup() {
+		ctx.Bot.Logger.Log(LevelDebug, "group only command in private message", F(FieldUserId, ctx.UserId), F("command", command.Name))
+		return true
+	}
+	if ctx.checkPermission(command.Permission, "command:"+command.Name) {
+		command.Handler(ctx, args)
+	}
+	return true
+}
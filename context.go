@@ -0,0 +1,161 @@
+package pbbot
+
+import (
+	"errors"
+
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/ProtobufBot/go-pbbot/storage"
+)
+
+const (
+	MessageTypePrivate = "private"
+	MessageTypeGroup   = "group"
+)
+
+var ErrNotGroupMessage = errors.New("not a group message")
+
+// DefaultStore MessageContext 中 BotStore、GroupStore、UserStore 使用的存储，可以替换为 storage.NewFileStore
+var DefaultStore storage.Store = storage.NewMemoryStore()
+
+// MessageContext 私聊消息和群聊消息的统一封装，Reply 等方法根据消息类型调用对应的API
+type MessageContext struct {
+	Bot         *Bot
+	MessageType string
+	MessageId   int32
+	GroupId     int64
+	UserId      int64
+	Msg         *Msg
+	RawMessage  string
+
+	PrivateMessageEvent *onebot.PrivateMessageEvent
+	GroupMessageEvent   *onebot.GroupMessageEvent
+}
+
+func NewPrivateMessageContext(bot *Bot, event *onebot.PrivateMessageEvent) *MessageContext {
+	return &MessageContext{
+		Bot:                 bot,
+		MessageType:         MessageTypePrivate,
+		MessageId:           event.MessageId,
+		UserId:              event.UserId,
+		Msg:                 ParseMsg(event.Message),
+		RawMessage:          event.RawMessage,
+		PrivateMessageEvent: event,
+	}
+}
+
+func NewGroupMessageContext(bot *Bot, event *onebot.GroupMessageEvent) *MessageContext {
+	return &MessageContext{
+		Bot:               bot,
+		MessageType:       MessageTypeGroup,
+		MessageId:         event.MessageId,
+		GroupId:           event.GroupId,
+		UserId:            event.UserId,
+		Msg:               ParseMsg(event.Message),
+		RawMessage:        event.RawMessage,
+		GroupMessageEvent: event,
+	}
+}
+
+func (ctx *MessageContext) IsGroup() bool {
+	return ctx.MessageType == MessageTypeGroup
+}
+
+// MentionsMe 消息中是否@了机器人，私聊消息总是返回 true
+func (ctx *MessageContext) MentionsMe() bool {
+	return !ctx.IsGroup() || ctx.Msg.MentionsMe(ctx.Bot.BotId)
+}
+
+// Target 消息来源
+func (ctx *MessageContext) Target() Target {
+	if ctx.IsGroup() {
+		return Group(ctx.GroupId)
+	}
+	return User(ctx.UserId)
+}
+
+// Reply 向消息来源发送消息，返回消息ID
+func (ctx *MessageContext) Reply(msg *Msg) (int32, error) {
+	resp, err := ctx.Bot.Send(ctx.Bot.Context(), ctx.Target(), msg)
+	if err != nil {
+		return 0, err
+	}
+	return resp.GetMessageId(), nil
+}
+
+// ReplyText 回复纯文本
+func (ctx *MessageContext) ReplyText(text string) (int32, error) {
+	return ctx.Reply(NewMsg().Text(text))
+}
+
+// ReplyQuote 引用当前消息回复
+func (ctx *MessageContext) ReplyQuote(msg *Msg) (int32, error) {
+	return ctx.Reply(prependMsg(NewMsg().Reply(ctx.MessageId), msg))
+}
+
+// ReplyAt 群聊中@发送者回复，私聊中与 Reply 相同
+func (ctx *MessageContext) ReplyAt(msg *Msg) (int32, error) {
+	if !ctx.IsGroup() {
+		return ctx.Reply(msg)
+	}
+	return ctx.Reply(prependMsg(NewMsg().At(ctx.UserId).Text(" "), msg))
+}
+
+// Quoted 从 MessageHistory 中查找当前消息引用的消息
+func (ctx *MessageContext) Quoted() (*HistoryRecord, bool) {
+	reply := ctx.Msg.GetReply()
+	if reply == nil || MessageHistory == nil {
+		return nil, false
+	}
+	return MessageHistory.Get(ctx.Bot.BotId, reply.Id)
+}
+
+// BotStore 当前机器人的存储
+func (ctx *MessageContext) BotStore() *storage.Bucket {
+	return storage.NewBucket(DefaultStore, storage.BotNamespace(ctx.Bot.BotId))
+}
+
+// GroupStore 当前群的存储，私聊消息返回 nil
+func (ctx *MessageContext) GroupStore() *storage.Bucket {
+	if !ctx.IsGroup() {
+		return nil
+	}
+	return storage.NewBucket(DefaultStore, storage.GroupNamespace(ctx.Bot.BotId, ctx.GroupId))
+}
+
+// UserStore 当前发送者的存储
+func (ctx *MessageContext) UserStore() *storage.Bucket {
+	return storage.NewBucket(DefaultStore, storage.UserNamespace(ctx.Bot.BotId, ctx.UserId))
+}
+
+// Recall 撤回当前消息，群聊中需要机器人是管理员
+func (ctx *MessageContext) Recall() error {
+	_, err := ctx.Bot.DeleteMsg(ctx.MessageId)
+	return err
+}
+
+// BanSender 禁言发送者，duration 单位秒，0 表示解除禁言
+func (ctx *MessageContext) BanSender(duration int32) error {
+	if !ctx.IsGroup() {
+		return ErrNotGroupMessage
+	}
+	_, err := ctx.Bot.SetGroupBan(ctx.GroupId, ctx.UserId, duration)
+	return err
+}
+
+// KickSender 把发送者踢出群
+func (ctx *MessageContext) KickSender(rejectAddRequest bool) error {
+	if !ctx.IsGroup() {
+		return ErrNotGroupMessage
+	}
+	_, err := ctx.Bot.SetGroupKick(ctx.GroupId, ctx.UserId, rejectAddRequest)
+	return err
+}
+
+// prependMsg 把 prefix 放在 msg 前面，保留 msg 的构造错误
+func prependMsg(prefix *Msg, msg *Msg) *Msg {
+	prefix.MessageList = append(prefix.MessageList, msg.MessageList...)
+	if err := msg.Err(); err != nil {
+		prefix.setErr(err)
+	}
+	return prefix
+}
@@ -0,0 +1,148 @@
+package cqcode
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+var (
+	textEscaper = strings.NewReplacer(
+		"&", "&amp;",
+		"[", "&#91;",
+		"]", "&#93;",
+	)
+	paramEscaper = strings.NewReplacer(
+		"&", "&amp;",
+		"[", "&#91;",
+		"]", "&#93;",
+		",", "&#44;",
+	)
+	unescaper = strings.NewReplacer(
+		"&#44;", ",",
+		"&#91;", "[",
+		"&#93;", "]",
+		"&amp;", "&",
+	)
+)
+
+// EscapeText 转义纯文本中的 & [ ]
+func EscapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+// EscapeParam 转义CQ码参数值中的 & [ ] ,
+func EscapeParam(param string) string {
+	return paramEscaper.Replace(param)
+}
+
+// Unescape 反转义
+func Unescape(s string) string {
+	return unescaper.Replace(s)
+}
+
+// Marshal 消息段列表转换为CQ码字符串，参数按键名排序
+func Marshal(messageList []*onebot.Message) string {
+	var sb strings.Builder
+	for _, message := range messageList {
+		if message == nil {
+			continue
+		}
+		if message.Type == "text" {
+			sb.WriteString(EscapeText(message.Data["text"]))
+			continue
+		}
+		keys := make([]string, 0, len(message.Data))
+		for k := range message.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sb.WriteString("[CQ:")
+		sb.WriteString(message.Type)
+		for _, k := range keys {
+			sb.WriteString(",")
+			sb.WriteString(k)
+			sb.WriteString("=")
+			sb.WriteString(EscapeParam(message.Data[k]))
+		}
+		sb.WriteString("]")
+	}
+	return sb.String()
+}
+
+// Unmarshal CQ码字符串转换为消息段列表，不完整的CQ码按纯文本处理
+func Unmarshal(str string) []*onebot.Message {
+	messageList := make([]*onebot.Message, 0)
+	var text strings.Builder
+	flushText := func() {
+		if text.Len() == 0 {
+			return
+		}
+		messageList = append(messageList, newText(Unescape(text.String())))
+		text.Reset()
+	}
+	for len(str) > 0 {
+		start := strings.Index(str, "[CQ:")
+		if start < 0 {
+			text.WriteString(str)
+			break
+		}
+		text.WriteString(str[:start])
+		str = str[start:]
+		end := strings.Index(str, "]")
+		if end < 0 {
+			text.WriteString(str)
+			break
+		}
+		message := parseCode(str[len("[CQ:"):end])
+		if message == nil {
+			text.WriteString(str[:1])
+			str = str[1:]
+			continue
+		}
+		flushText()
+		messageList = append(messageList, message)
+		str = str[end+1:]
+	}
+	flushText()
+	return messageList
+}
+
+// Text 不解析CQ码，整个字符串作为纯文本，对应 auto_escape 为 true 的情况
+func Text(str string) []*onebot.Message {
+	if str == "" {
+		return make([]*onebot.Message, 0)
+	}
+	return []*onebot.Message{newText(str)}
+}
+
+// parseCode 解析 "type,k1=v1,k2=v2"，类型为空时返回 nil
+func parseCode(code string) *onebot.Message {
+	parts := strings.Split(code, ",")
+	messageType := strings.TrimSpace(parts[0])
+	if messageType == "" || strings.ContainsAny(messageType, "[=") {
+		return nil
+	}
+	data := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil
+		}
+		data[kv[0]] = Unescape(kv[1])
+	}
+	return &onebot.Message{
+		Type: messageType,
+		Data: data,
+	}
+}
+
+func newText(text string) *onebot.Message {
+	return &onebot.Message{
+		Type: "text",
+		Data: map[string]string{
+			"text": text,
+		},
+	}
+}
@@ -13,7 +13,27 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// Server websocket 服务，Logger 为 nil 时使用 DefaultLogger
+type Server struct {
+	Logger Logger
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.Upgrade(w, r); err != nil {
+		logger := s.Logger
+		if logger == nil {
+			logger = DefaultLogger
+		}
+		logger.Log(LevelError, "failed to upgrade websocket", Err(err), F("remote_addr", r.RemoteAddr))
+	}
+}
+
 func UpgradeWebsocket(w http.ResponseWriter, r *http.Request) error {
+	return (&Server{}).Upgrade(w, r)
+}
+
+// Upgrade 升级为 websocket 连接并创建机器人
+func (s *Server) Upgrade(w http.ResponseWriter, r *http.Request) error {
 	xSelfId := r.Header.Get("x-self-id")
 	botId, err := strconv.ParseInt(xSelfId, 10, 64)
 	if err != nil {
@@ -23,6 +43,6 @@ func UpgradeWebsocket(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
-	NewBot(botId, c)
+	NewBotWithLogger(botId, c, s.Logger)
 	return nil
 }
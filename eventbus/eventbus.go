@@ -0,0 +1,213 @@
+// Package eventbus 把机器人收到的事件和发出的API请求发布到消息总线
+//
+// 目前只提供 NATS 的 Sink（NatsSink），以及用于测试和单进程消费的 MemorySink，
+// 不提供 Kafka、Redis 等其他消息总线的实现，需要时实现 Sink 接口即可
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/ProtobufBot/go-pbbot/util"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	ErrPublisherClosed = errors.New("eventbus: publisher closed")
+	ErrQueueFull       = errors.New("eventbus: queue is full")
+)
+
+// 事件编码
+const (
+	EncodingProtobuf = "protobuf"
+	EncodingJSON     = "json"
+)
+
+// Event 发布到消息总线的事件，Data 为按 Encoding 编码的 onebot.Frame
+type Event struct {
+	Subject   string
+	BotId     int64
+	FrameType string
+	Outbound  bool
+	Time      time.Time
+	Encoding  string
+	Data      []byte
+}
+
+// Sink 消息总线
+type Sink interface {
+	Publish(ctx context.Context, event *Event) error
+	Close() error
+}
+
+// Options 发布选项
+type Options struct {
+	// SubjectPrefix 主题前缀，事件主题为 <prefix>.<bot_id>.event.<frame_type>，API调用为 <prefix>.<bot_id>.api.<frame_type>
+	SubjectPrefix string
+	// Encoding 事件编码，默认为 protobuf
+	Encoding string
+	// PublishApiCalls 是否发布机器人发出的API请求
+	PublishApiCalls bool
+	// QueueSize 发布队列大小，队列满时丢弃事件
+	QueueSize int
+	// Timeout 单次发布超时时间
+	Timeout time.Duration
+}
+
+var DefaultOptions = Options{
+	SubjectPrefix: "pbbot",
+	Encoding:      EncodingProtobuf,
+	QueueSize:     1024,
+	Timeout:       5 * time.Second,
+}
+
+// Publisher 把收到的事件异步发布到 Sink，不会阻塞事件处理
+type Publisher struct {
+	Sink    Sink
+	Options Options
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan *Event
+	stopped chan struct{}
+}
+
+// NewPublisher options 为 nil 时使用 DefaultOptions
+func NewPublisher(sink Sink, options *Options) *Publisher {
+	if options == nil {
+		options = &DefaultOptions
+	}
+	opts := *options
+	if opts.SubjectPrefix == "" {
+		opts.SubjectPrefix = DefaultOptions.SubjectPrefix
+	}
+	if opts.Encoding == "" {
+		opts.Encoding = DefaultOptions.Encoding
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultOptions.QueueSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultOptions.Timeout
+	}
+	p := &Publisher{
+		Sink:    sink,
+		Options: opts,
+		queue:   make(chan *Event, opts.QueueSize),
+		stopped: make(chan struct{}),
+	}
+	util.SafeGo(p.run)
+	return p
+}
+
+// Install 注册帧拦截器，PublishApiCalls 为 true 时同时注册API拦截器，只需要调用一次
+func (p *Publisher) Install() {
+	pbbot.UseFrameInterceptor(func(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+		if frame.FrameType < 300 {
+			_ = p.Publish(bot, frame, false)
+		}
+		next(ctx)
+	})
+	if p.Options.PublishApiCalls {
+		pbbot.UseApiInterceptor(func(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+			_ = p.Publish(bot, req, true)
+			return next(ctx)
+		})
+	}
+}
+
+// Close 停止接收新事件，等待队列中的事件发布完成后关闭 Sink
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPublisherClosed
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+	<-p.stopped
+	return p.Sink.Close()
+}
+
+// Subject 事件的主题
+func (p *Publisher) Subject(botId int64, frame *onebot.Frame, outbound bool) string {
+	kind := "event"
+	if outbound {
+		kind = "api"
+	}
+	return p.Options.SubjectPrefix + "." + strconv.FormatInt(botId, 10) + "." + kind + "." + frame.FrameType.String()
+}
+
+// Publish 把帧加入发布队列，outbound 表示机器人发出的API请求，Close 之后返回 ErrPublisherClosed
+func (p *Publisher) Publish(bot *pbbot.Bot, frame *onebot.Frame, outbound bool) error {
+	data, err := encode(frame, p.Options.Encoding)
+	if err != nil {
+		bot.Logger.Log(pbbot.LevelError, "failed to encode event", pbbot.F(pbbot.FieldFrameType, frame.FrameType.String()), pbbot.Err(err))
+		return err
+	}
+	event := &Event{
+		Subject:   p.Subject(bot.BotId, frame, outbound),
+		BotId:     bot.BotId,
+		FrameType: frame.FrameType.String(),
+		Outbound:  outbound,
+		Time:      time.Now(),
+		Encoding:  p.Options.Encoding,
+		Data:      data,
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPublisherClosed
+	}
+	select {
+	case p.queue <- event:
+		return nil
+	default:
+		bot.Logger.Log(pbbot.LevelWarn, "event bus queue is full, event dropped", pbbot.F(pbbot.FieldFrameType, event.FrameType))
+		return ErrQueueFull
+	}
+}
+
+// run 发布队列中的事件，队列关闭并取完后退出
+func (p *Publisher) run() {
+	defer close(p.stopped)
+	for event := range p.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), p.Options.Timeout)
+		if err := p.Sink.Publish(ctx, event); err != nil {
+			pbbot.DefaultLogger.Log(pbbot.LevelError, "failed to publish event", pbbot.F(pbbot.FieldBotId, event.BotId),
+				pbbot.F(pbbot.FieldFrameType, event.FrameType), pbbot.F("subject", event.Subject), pbbot.Err(err))
+		}
+		cancel()
+	}
+}
+
+func encode(frame *onebot.Frame, encoding string) ([]byte, error) {
+	if encoding == EncodingJSON {
+		marshaler := jsonpb.Marshaler{OrigName: true}
+		data, err := marshaler.MarshalToString(frame)
+		return []byte(data), err
+	}
+	return proto.Marshal(frame)
+}
+
+// Decode 解码事件中的 onebot.Frame
+func Decode(event *Event) (*onebot.Frame, error) {
+	var frame onebot.Frame
+	if event.Encoding == EncodingJSON {
+		if err := jsonpb.UnmarshalString(string(event.Data), &frame); err != nil {
+			return nil, err
+		}
+		return &frame, nil
+	}
+	if err := proto.Unmarshal(event.Data, &frame); err != nil {
+		return nil, err
+	}
+	return &frame, nil
+}
@@ -0,0 +1,65 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrClosed = errors.New("eventbus: sink closed")
+
+// MemorySink 内存中的消息总线，用于测试和单进程内的消费者
+type MemorySink struct {
+	// Capacity 保留的最近事件数量，为 0 时不保留
+	Capacity int
+
+	mu          sync.Mutex
+	events      []*Event
+	subscribers []func(event *Event)
+	closed      bool
+}
+
+func NewMemorySink(capacity int) *MemorySink {
+	return &MemorySink{Capacity: capacity}
+}
+
+func (s *MemorySink) Publish(ctx context.Context, event *Event) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	if s.Capacity > 0 {
+		s.events = append(s.events, event)
+		if len(s.events) > s.Capacity {
+			s.events = append(s.events[:0:0], s.events[len(s.events)-s.Capacity:]...)
+		}
+	}
+	subscribers := s.subscribers
+	s.mu.Unlock()
+	for _, fn := range subscribers {
+		fn(event)
+	}
+	return nil
+}
+
+// Subscribe 发布时同步调用 fn
+func (s *MemorySink) Subscribe(fn func(event *Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Events 保留的最近事件，按发布顺序
+func (s *MemorySink) Events() []*Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Event(nil), s.events...)
+}
+
+func (s *MemorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
@@ -0,0 +1,39 @@
+package eventbus
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NatsSink 发布到 NATS，事件主题即 NATS 主题，机器人和帧类型放在消息头中
+type NatsSink struct {
+	Conn *nats.Conn
+}
+
+func NewNatsSink(conn *nats.Conn) *NatsSink {
+	return &NatsSink{Conn: conn}
+}
+
+func (s *NatsSink) Publish(ctx context.Context, event *Event) error {
+	msg := nats.NewMsg(event.Subject)
+	msg.Data = event.Data
+	msg.Header.Set("Pbbot-Bot-Id", strconv.FormatInt(event.BotId, 10))
+	msg.Header.Set("Pbbot-Frame-Type", event.FrameType)
+	msg.Header.Set("Pbbot-Encoding", event.Encoding)
+	msg.Header.Set("Pbbot-Time", event.Time.Format(time.RFC3339Nano))
+	if event.Outbound {
+		msg.Header.Set("Pbbot-Outbound", "1")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Conn.PublishMsg(msg)
+}
+
+// Close 发送缓冲区中的消息后关闭连接
+func (s *NatsSink) Close() error {
+	return s.Conn.Drain()
+}
@@ -0,0 +1,60 @@
+package pbbot
+
+import (
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// ForwardMsg 合并转发消息，由多个 node 消息段组成
+type ForwardMsg struct {
+	Nodes []*NodeSegment
+}
+
+func NewForwardMsg() *ForwardMsg {
+	return &ForwardMsg{
+		Nodes: make([]*NodeSegment, 0),
+	}
+}
+
+// Node 引用已有的消息
+func (forwardMsg *ForwardMsg) Node(messageId int32) *ForwardMsg {
+	forwardMsg.Nodes = append(forwardMsg.Nodes, &NodeSegment{Id: messageId})
+	return forwardMsg
+}
+
+// CustomNode 自定义节点，显示为 uin 发送的 content
+func (forwardMsg *ForwardMsg) CustomNode(name string, uin int64, content *Msg) *ForwardMsg {
+	forwardMsg.Nodes = append(forwardMsg.Nodes, &NodeSegment{
+		Name:    name,
+		Uin:     uin,
+		Content: content.Segments(),
+	})
+	return forwardMsg
+}
+
+// Msg 转换为可以直接发送的消息
+func (forwardMsg *ForwardMsg) Msg() *Msg {
+	msg := NewMsg()
+	for _, node := range forwardMsg.Nodes {
+		msg.Append(node)
+	}
+	return msg
+}
+
+// ParseForwardMsg 从消息段列表中解析合并转发节点，非 node 消息段会被忽略
+func ParseForwardMsg(messageList []*onebot.Message) *ForwardMsg {
+	forwardMsg := NewForwardMsg()
+	for _, segment := range ParseSegments(messageList) {
+		if node, ok := segment.(*NodeSegment); ok {
+			forwardMsg.Nodes = append(forwardMsg.Nodes, node)
+		}
+	}
+	return forwardMsg
+}
+
+func (bot *Bot) SendGroupForwardMsg(groupId int64, forwardMsg *ForwardMsg) (*onebot.SendGroupMsgResp, error) {
+	return bot.SendGroupMessage(groupId, forwardMsg.Msg(), false)
+}
+
+func (bot *Bot) SendPrivateForwardMsg(userId int64, forwardMsg *ForwardMsg) (*onebot.SendPrivateMsgResp, error) {
+	return bot.SendPrivateMessage(userId, forwardMsg.Msg(), false)
+}
@@ -0,0 +1,161 @@
+package gateway
+
+import (
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// action 解析请求体并调用对应的 Bot 方法，返回值序列化为响应体
+type action func(bot *pbbot.Bot, body []byte) (interface{}, error)
+
+// actions 动作名与 onebot 的API名一致，请求体为对应的 onebot 请求结构
+var actions = map[string]action{
+	"send_private_msg": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SendPrivateMsgReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		if len(req.Message) == 0 {
+			return nil, badRequest("empty message")
+		}
+		return bot.SendPrivateMessage(req.UserId, pbbot.ParseMsg(req.Message), req.AutoEscape)
+	},
+	"send_group_msg": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SendGroupMsgReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		if len(req.Message) == 0 {
+			return nil, badRequest("empty message")
+		}
+		return bot.SendGroupMessage(req.GroupId, pbbot.ParseMsg(req.Message), req.AutoEscape)
+	},
+	"send_msg": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SendMsgReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		if len(req.Message) == 0 {
+			return nil, badRequest("empty message")
+		}
+		if req.MessageType != pbbot.MessageTypePrivate && req.MessageType != pbbot.MessageTypeGroup {
+			return nil, badRequest("invalid message_type " + req.MessageType)
+		}
+		target := pbbot.Target{MessageType: req.MessageType, UserId: req.UserId, GroupId: req.GroupId}
+		return bot.Send(bot.Context(), target, pbbot.ParseMsg(req.Message))
+	},
+	"delete_msg": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.DeleteMsgReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.DeleteMsg(req.MessageId)
+	},
+	"get_msg": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.GetMsgReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.GetMsg(req.MessageId)
+	},
+	"get_forward_msg": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.GetForwardMsgReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.GetForwardMsg(req.Id)
+	},
+	"set_group_kick": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SetGroupKickReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.SetGroupKick(req.GroupId, req.UserId, req.RejectAddRequest)
+	},
+	"set_group_ban": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SetGroupBanReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.SetGroupBan(req.GroupId, req.UserId, req.Duration)
+	},
+	"set_group_whole_ban": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SetGroupWholeBanReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.SetGroupWholeBan(req.GroupId, req.Enable)
+	},
+	"set_group_card": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SetGroupCardReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.SetGroupCard(req.GroupId, req.UserId, req.Card)
+	},
+	"set_group_leave": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SetGroupLeaveReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.SetGroupLeave(req.GroupId, req.IsDismiss)
+	},
+	"set_group_special_title": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SetGroupSpecialTitleReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.SetGroupSpecialTitle(req.GroupId, req.UserId, req.SpecialTitle, req.Duration)
+	},
+	"set_friend_add_request": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SetFriendAddRequestReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.SetFriendAddRequest(req.Flag, req.Approve, req.Remark)
+	},
+	"set_group_add_request": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.SetGroupAddRequestReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.SetGroupAddRequestWithSubType(req.Flag, req.SubType, req.Approve, req.Reason)
+	},
+	"get_login_info": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		return bot.GetLoginInfo()
+	},
+	"get_stranger_info": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.GetStrangerInfoReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.GetStrangerInfo(req.UserId, req.NoCache)
+	},
+	"get_friend_list": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		return bot.GetFriendList()
+	},
+	"get_group_list": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		return bot.GetGroupList()
+	},
+	"get_group_info": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.GetGroupInfoReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.GetGroupInfo(req.GroupId, req.NoCache)
+	},
+	"get_group_member_info": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.GetGroupMemberInfoReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.GetGroupMemberInfo(req.GroupId, req.UserId, req.NoCache)
+	},
+	"get_group_member_list": func(bot *pbbot.Bot, body []byte) (interface{}, error) {
+		var req onebot.GetGroupMemberListReq
+		if err := decode(body, &req); err != nil {
+			return nil, err
+		}
+		return bot.GetGroupMemberList(req.GroupId)
+	},
+}
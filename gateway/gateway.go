@@ -0,0 +1,177 @@
+package gateway
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ProtobufBot/go-pbbot"
+)
+
+// MaxBodySize 请求体大小限制
+var MaxBodySize int64 = 1 << 20
+
+// 错误码，与 HTTP 状态码一起返回
+const (
+	CodeUnauthorized  = "unauthorized"
+	CodeNotFound      = "not_found"
+	CodeBadRequest    = "bad_request"
+	CodeBotOffline    = "bot_offline"
+	CodeTimeout       = "timeout"
+	CodeCancelled     = "cancelled"
+	CodeInternalError = "internal_error"
+)
+
+// ErrorResp 错误响应
+type ErrorResp struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type badRequestError struct {
+	err error
+}
+
+func (e *badRequestError) Error() string {
+	return e.err.Error()
+}
+
+// Gateway 通过 HTTP 调用机器人API，POST /bots/{id}/{action}，请求体和响应体为 onebot 结构的 JSON
+// Token 不为空时要求 Authorization: Bearer <Token>，Authorize 不为 nil 时由其决定是否允许请求
+// 两者都没有设置时拒绝所有请求，除非 AllowAnonymous 为 true
+type Gateway struct {
+	Token          string
+	Authorize      func(r *http.Request) bool
+	AllowAnonymous bool
+}
+
+// New 使用 token 鉴权
+func New(token string) *Gateway {
+	return &Gateway{Token: token}
+}
+
+// Actions 支持的动作
+func Actions() []string {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !g.authorized(r) {
+		writeError(w, http.StatusUnauthorized, CodeUnauthorized, "unauthorized")
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "bots" {
+		writeError(w, http.StatusNotFound, CodeNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, CodeBadRequest, "method not allowed")
+		return
+	}
+	botId, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "invalid bot id")
+		return
+	}
+	call, ok := actions[parts[2]]
+	if !ok {
+		writeError(w, http.StatusNotFound, CodeNotFound, "unknown action "+parts[2])
+		return
+	}
+	bot, ok := pbbot.GetBot(botId)
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, CodeBotOffline, "bot "+parts[1]+" offline")
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if err != nil {
+		status := http.StatusBadRequest
+		if tooLarge(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, CodeBadRequest, err.Error())
+		return
+	}
+	resp, err := call(bot.WithContext(r.Context()), body)
+	if err != nil {
+		status, code := errorStatus(err)
+		if status >= http.StatusInternalServerError {
+			bot.Logger.Log(pbbot.LevelError, "gateway action failed", pbbot.F("action", parts[2]), pbbot.Err(err))
+		}
+		writeError(w, status, code, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (g *Gateway) authorized(r *http.Request) bool {
+	if g.Authorize != nil {
+		return g.Authorize(r)
+	}
+	if g.Token == "" {
+		return g.AllowAnonymous
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(g.Token)) == 1
+}
+
+func errorStatus(err error) (int, string) {
+	var badRequest *badRequestError
+	switch {
+	case errors.As(err, &badRequest):
+		return http.StatusBadRequest, CodeBadRequest
+	case errors.Is(err, pbbot.ErrDisconnected):
+		return http.StatusServiceUnavailable, CodeBotOffline
+	case errors.Is(err, pbbot.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, CodeCancelled
+	default:
+		return http.StatusBadGateway, CodeInternalError
+	}
+}
+
+// tooLarge 请求体是否超过 MaxBodySize，go 1.19 之前 MaxBytesReader 没有导出错误类型，只能比较错误信息
+func tooLarge(err error) bool {
+	return err.Error() == "http: request body too large"
+}
+
+func decode(body []byte, v interface{}) error {
+	if len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return &badRequestError{err: err}
+	}
+	return nil
+}
+
+func badRequest(message string) error {
+	return &badRequestError{err: errors.New(message)}
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, &ErrorResp{Code: code, Message: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,414 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: onebot_service.proto
+
+package grpcserver
+
+import (
+	fmt "fmt"
+	_ "github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	proto "github.com/golang/protobuf/proto"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type SubscribeEventsReq struct {
+	BotIds               []int64  `protobuf:"varint,1,rep,packed,name=bot_ids,json=botIds,proto3" json:"bot_ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribeEventsReq) Reset()         { *m = SubscribeEventsReq{} }
+func (m *SubscribeEventsReq) String() string { return proto.CompactTextString(m) }
+func (*SubscribeEventsReq) ProtoMessage()    {}
+func (*SubscribeEventsReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_26da4f7e268e7ed1, []int{0}
+}
+func (m *SubscribeEventsReq) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SubscribeEventsReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SubscribeEventsReq.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SubscribeEventsReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeEventsReq.Merge(m, src)
+}
+func (m *SubscribeEventsReq) XXX_Size() int {
+	return m.Size()
+}
+func (m *SubscribeEventsReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeEventsReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeEventsReq proto.InternalMessageInfo
+
+func (m *SubscribeEventsReq) GetBotIds() []int64 {
+	if m != nil {
+		return m.BotIds
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*SubscribeEventsReq)(nil), "onebot.SubscribeEventsReq")
+}
+
+func init() { proto.RegisterFile("onebot_service.proto", fileDescriptor_26da4f7e268e7ed1) }
+
+var fileDescriptor_26da4f7e268e7ed1 = []byte{
+	// 610 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x95, 0xdf, 0x4e, 0x13, 0x41,
+	0x14, 0xc6, 0x6d, 0x48, 0x4a, 0x1c, 0x41, 0xf0, 0x80, 0x02, 0x8b, 0x2c, 0xc4, 0x2b, 0x63, 0x42,
+	0x31, 0x78, 0xe3, 0x85, 0x31, 0xa1, 0x22, 0x1b, 0x94, 0x46, 0xd2, 0x35, 0x1a, 0xbd, 0x31, 0xfb,
+	0xe7, 0xb4, 0x4c, 0x6c, 0x77, 0x86, 0x99, 0x69, 0x7d, 0x15, 0x6f, 0x7d, 0x1b, 0x2f, 0x7d, 0x04,
+	0x53, 0x5f, 0xc4, 0xcc, 0x6e, 0x67, 0x77, 0xf6, 0x4f, 0x7b, 0x07, 0xbf, 0xef, 0x3b, 0xdf, 0xee,
+	0x77, 0x26, 0xdb, 0x21, 0xdb, 0x2c, 0xc1, 0x90, 0xa9, 0x6f, 0x12, 0xc5, 0x94, 0x46, 0xd8, 0xe1,
+	0x82, 0x29, 0x06, 0xed, 0x8c, 0x3a, 0x9b, 0x73, 0x35, 0xe0, 0x34, 0x53, 0x1c, 0x98, 0x93, 0x81,
+	0x08, 0xc6, 0x73, 0xf7, 0x93, 0x63, 0x02, 0xfe, 0x24, 0x94, 0x91, 0xa0, 0x21, 0xbe, 0x9d, 0x62,
+	0xa2, 0x64, 0x1f, 0x6f, 0x61, 0x87, 0xac, 0x6a, 0x23, 0x8d, 0xe5, 0x6e, 0xeb, 0x68, 0xe5, 0xe9,
+	0x4a, 0xbf, 0x1d, 0x32, 0x75, 0x19, 0xcb, 0xd3, 0x5f, 0x6b, 0xa4, 0xfd, 0x21, 0xc1, 0x2e, 0x53,
+	0xe0, 0x91, 0xfb, 0x3e, 0x26, 0xf1, 0xb5, 0xa0, 0xd3, 0x40, 0x61, 0x4f, 0x0e, 0x61, 0xaf, 0x93,
+	0x3d, 0xa0, 0x53, 0xe6, 0x7d, 0xbc, 0x75, 0x9c, 0x45, 0x92, 0xe4, 0x70, 0x46, 0xd6, 0x34, 0xf5,
+	0x04, 0x9b, 0x70, 0x1d, 0xb3, 0x63, 0x7b, 0x0d, 0xd5, 0x21, 0xbb, 0xcd, 0x82, 0xe4, 0x70, 0x4a,
+	0x56, 0x35, 0xd3, 0xd3, 0x60, 0x9b, 0xe6, 0x83, 0x5b, 0x35, 0x26, 0x39, 0xbc, 0x24, 0x77, 0xcf,
+	0x71, 0x84, 0xd9, 0xab, 0x6f, 0x1b, 0x47, 0x8e, 0xf4, 0xdc, 0xc3, 0x06, 0x2a, 0x39, 0x9c, 0x90,
+	0xb6, 0x87, 0x4a, 0x8f, 0x3d, 0x30, 0x86, 0xec, 0x7f, 0x3d, 0x03, 0x55, 0x24, 0x39, 0x9c, 0x93,
+	0x75, 0x0f, 0xd5, 0x05, 0x13, 0x3f, 0x02, 0x91, 0xbe, 0xe4, 0xae, 0x65, 0x2a, 0xb0, 0x1e, 0xdf,
+	0x5b, 0xa0, 0x98, 0x3d, 0xa9, 0xb4, 0xf7, 0x7b, 0x1a, 0x7d, 0xb7, 0xf7, 0x54, 0xd0, 0xca, 0x9e,
+	0x6c, 0x41, 0x72, 0x78, 0x4d, 0xee, 0x19, 0xd6, 0x0d, 0x12, 0x78, 0x54, 0x35, 0x76, 0x83, 0x44,
+	0x07, 0xec, 0x34, 0x72, 0xc9, 0xa1, 0x47, 0x36, 0x0d, 0xfa, 0x7c, 0xc3, 0x46, 0xa8, 0x43, 0xf6,
+	0xab, 0x66, 0xa3, 0xe8, 0xa4, 0xc7, 0x8b, 0xc5, 0x72, 0xa3, 0x37, 0x81, 0x88, 0xeb, 0x8d, 0x34,
+	0x6d, 0x6c, 0x94, 0x09, 0xd9, 0x6a, 0x0d, 0xbb, 0xc2, 0x60, 0x8a, 0x50, 0xb3, 0xa6, 0xb8, 0xb4,
+	0xda, 0x8a, 0x22, 0x39, 0x7c, 0x21, 0xdb, 0x06, 0xfa, 0x1c, 0x23, 0x1a, 0x8c, 0x3e, 0x52, 0x35,
+	0x42, 0x38, 0xac, 0x8e, 0xd8, 0xaa, 0xce, 0x3c, 0x5a, 0x6e, 0x90, 0x1c, 0x3e, 0x91, 0x2d, 0x1f,
+	0xd5, 0x85, 0xa0, 0x98, 0xc4, 0x67, 0xb1, 0x6e, 0x33, 0x41, 0xa9, 0xc0, 0xb5, 0x06, 0xab, 0xa2,
+	0x0e, 0x3e, 0x5c, 0xaa, 0x4b, 0x0e, 0x3e, 0x01, 0xf3, 0x4c, 0x2b, 0xf6, 0xa0, 0xfa, 0x3e, 0xe5,
+	0x54, 0x77, 0x99, 0x9c, 0x1d, 0x88, 0x87, 0xea, 0x8a, 0x0d, 0x69, 0x72, 0x99, 0x0c, 0x58, 0x71,
+	0x20, 0x36, 0x2d, 0x1d, 0x48, 0x59, 0x90, 0x1c, 0xde, 0x91, 0x0d, 0x0f, 0x95, 0xaf, 0x44, 0x90,
+	0x0c, 0x51, 0xa4, 0x29, 0x8e, 0x65, 0xb6, 0x05, 0x1d, 0xb4, 0xbf, 0x50, 0x2b, 0xbe, 0x9b, 0xb4,
+	0xfe, 0x15, 0x95, 0xaa, 0xfc, 0xdd, 0xe4, 0xb8, 0xf6, 0xdd, 0x58, 0x4a, 0x5e, 0x2a, 0x3b, 0x71,
+	0x1d, 0x62, 0x97, 0xca, 0x69, 0xb5, 0x94, 0x25, 0x94, 0x23, 0x6a, 0x7b, 0xc9, 0x69, 0x63, 0x44,
+	0xde, 0xc5, 0x27, 0x60, 0x58, 0x0f, 0xc7, 0xe1, 0x7c, 0x35, 0x07, 0x55, 0x7f, 0xa1, 0x95, 0xce,
+	0xab, 0x49, 0x6e, 0x0a, 0x4d, 0x0b, 0x2e, 0x08, 0x35, 0x35, 0xdd, 0x65, 0x72, 0xfa, 0x23, 0xb1,
+	0x51, 0xb9, 0x12, 0x8a, 0x13, 0xac, 0xdf, 0x15, 0xce, 0xba, 0xd1, 0x2e, 0xf4, 0xb5, 0xf2, 0xbc,
+	0xd5, 0x7d, 0xf5, 0x7b, 0xe6, 0xb6, 0xfe, 0xcc, 0xdc, 0xd6, 0xdf, 0x99, 0xdb, 0xfa, 0xf9, 0xcf,
+	0xbd, 0xf3, 0xf5, 0xd9, 0x90, 0xaa, 0x9b, 0x49, 0xd8, 0x89, 0xd8, 0xf8, 0xe4, 0x5a, 0x30, 0xc5,
+	0xc2, 0xc9, 0xa0, 0xcb, 0xd4, 0xc9, 0x90, 0x1d, 0xf3, 0x30, 0xd4, 0x7f, 0x08, 0x1e, 0xe9, 0x5b,
+	0x0c, 0x45, 0xd8, 0x4e, 0xef, 0xa5, 0x17, 0xff, 0x07, 0x00, 0x70, 0x9d, 0x14, 0x6d, 0xdd, 0x06,
+	0x00, 0x00,
+}
+
+func (m *SubscribeEventsReq) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SubscribeEventsReq) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SubscribeEventsReq) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.BotIds) > 0 {
+		dAtA2 := make([]byte, len(m.BotIds)*10)
+		var j1 int
+		for _, num1 := range m.BotIds {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		i -= j1
+		copy(dAtA[i:], dAtA2[:j1])
+		i = encodeVarintOnebotService(dAtA, i, uint64(j1))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintOnebotService(dAtA []byte, offset int, v uint64) int {
+	offset -= sovOnebotService(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *SubscribeEventsReq) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.BotIds) > 0 {
+		l = 0
+		for _, e := range m.BotIds {
+			l += sovOnebotService(uint64(e))
+		}
+		n += 1 + sovOnebotService(uint64(l)) + l
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovOnebotService(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozOnebotService(x uint64) (n int) {
+	return sovOnebotService(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *SubscribeEventsReq) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOnebotService
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SubscribeEventsReq: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SubscribeEventsReq: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType == 0 {
+				var v int64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowOnebotService
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.BotIds = append(m.BotIds, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowOnebotService
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthOnebotService
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthOnebotService
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.BotIds) == 0 {
+					m.BotIds = make([]int64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowOnebotService
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.BotIds = append(m.BotIds, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field BotIds", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOnebotService(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthOnebotService
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipOnebotService(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowOnebotService
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowOnebotService
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowOnebotService
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthOnebotService
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupOnebotService
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthOnebotService
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthOnebotService        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowOnebotService          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupOnebotService = fmt.Errorf("proto: unexpected end of group")
+)
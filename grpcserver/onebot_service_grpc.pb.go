@@ -0,0 +1,892 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: onebot_service.proto
+
+package grpcserver
+
+import (
+	context "context"
+	onebot "github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// OneBotClient is the client API for OneBot service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OneBotClient interface {
+	SendPrivateMsg(ctx context.Context, in *onebot.SendPrivateMsgReq, opts ...grpc.CallOption) (*onebot.SendPrivateMsgResp, error)
+	SendGroupMsg(ctx context.Context, in *onebot.SendGroupMsgReq, opts ...grpc.CallOption) (*onebot.SendGroupMsgResp, error)
+	SendMsg(ctx context.Context, in *onebot.SendMsgReq, opts ...grpc.CallOption) (*onebot.SendMsgResp, error)
+	DeleteMsg(ctx context.Context, in *onebot.DeleteMsgReq, opts ...grpc.CallOption) (*onebot.DeleteMsgResp, error)
+	GetMsg(ctx context.Context, in *onebot.GetMsgReq, opts ...grpc.CallOption) (*onebot.GetMsgResp, error)
+	GetForwardMsg(ctx context.Context, in *onebot.GetForwardMsgReq, opts ...grpc.CallOption) (*onebot.GetForwardMsgResp, error)
+	SetGroupKick(ctx context.Context, in *onebot.SetGroupKickReq, opts ...grpc.CallOption) (*onebot.SetGroupKickResp, error)
+	SetGroupBan(ctx context.Context, in *onebot.SetGroupBanReq, opts ...grpc.CallOption) (*onebot.SetGroupBanResp, error)
+	SetGroupWholeBan(ctx context.Context, in *onebot.SetGroupWholeBanReq, opts ...grpc.CallOption) (*onebot.SetGroupWholeBanResp, error)
+	SetGroupCard(ctx context.Context, in *onebot.SetGroupCardReq, opts ...grpc.CallOption) (*onebot.SetGroupCardResp, error)
+	SetGroupLeave(ctx context.Context, in *onebot.SetGroupLeaveReq, opts ...grpc.CallOption) (*onebot.SetGroupLeaveResp, error)
+	SetGroupSpecialTitle(ctx context.Context, in *onebot.SetGroupSpecialTitleReq, opts ...grpc.CallOption) (*onebot.SetGroupSpecialTitleResp, error)
+	SetFriendAddRequest(ctx context.Context, in *onebot.SetFriendAddRequestReq, opts ...grpc.CallOption) (*onebot.SetFriendAddRequestResp, error)
+	SetGroupAddRequest(ctx context.Context, in *onebot.SetGroupAddRequestReq, opts ...grpc.CallOption) (*onebot.SetGroupAddRequestResp, error)
+	GetLoginInfo(ctx context.Context, in *onebot.GetLoginInfoReq, opts ...grpc.CallOption) (*onebot.GetLoginInfoResp, error)
+	GetStrangerInfo(ctx context.Context, in *onebot.GetStrangerInfoReq, opts ...grpc.CallOption) (*onebot.GetStrangerInfoResp, error)
+	GetFriendList(ctx context.Context, in *onebot.GetFriendListReq, opts ...grpc.CallOption) (*onebot.GetFriendListResp, error)
+	GetGroupList(ctx context.Context, in *onebot.GetGroupListReq, opts ...grpc.CallOption) (*onebot.GetGroupListResp, error)
+	GetGroupInfo(ctx context.Context, in *onebot.GetGroupInfoReq, opts ...grpc.CallOption) (*onebot.GetGroupInfoResp, error)
+	GetGroupMemberInfo(ctx context.Context, in *onebot.GetGroupMemberInfoReq, opts ...grpc.CallOption) (*onebot.GetGroupMemberInfoResp, error)
+	GetGroupMemberList(ctx context.Context, in *onebot.GetGroupMemberListReq, opts ...grpc.CallOption) (*onebot.GetGroupMemberListResp, error)
+	// SubscribeEvents 推送事件帧，bot_ids 为空时推送所有机器人的事件
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsReq, opts ...grpc.CallOption) (OneBot_SubscribeEventsClient, error)
+}
+
+type oneBotClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOneBotClient(cc grpc.ClientConnInterface) OneBotClient {
+	return &oneBotClient{cc}
+}
+
+func (c *oneBotClient) SendPrivateMsg(ctx context.Context, in *onebot.SendPrivateMsgReq, opts ...grpc.CallOption) (*onebot.SendPrivateMsgResp, error) {
+	out := new(onebot.SendPrivateMsgResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SendPrivateMsg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SendGroupMsg(ctx context.Context, in *onebot.SendGroupMsgReq, opts ...grpc.CallOption) (*onebot.SendGroupMsgResp, error) {
+	out := new(onebot.SendGroupMsgResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SendGroupMsg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SendMsg(ctx context.Context, in *onebot.SendMsgReq, opts ...grpc.CallOption) (*onebot.SendMsgResp, error) {
+	out := new(onebot.SendMsgResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SendMsg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) DeleteMsg(ctx context.Context, in *onebot.DeleteMsgReq, opts ...grpc.CallOption) (*onebot.DeleteMsgResp, error) {
+	out := new(onebot.DeleteMsgResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/DeleteMsg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetMsg(ctx context.Context, in *onebot.GetMsgReq, opts ...grpc.CallOption) (*onebot.GetMsgResp, error) {
+	out := new(onebot.GetMsgResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetMsg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetForwardMsg(ctx context.Context, in *onebot.GetForwardMsgReq, opts ...grpc.CallOption) (*onebot.GetForwardMsgResp, error) {
+	out := new(onebot.GetForwardMsgResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetForwardMsg", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SetGroupKick(ctx context.Context, in *onebot.SetGroupKickReq, opts ...grpc.CallOption) (*onebot.SetGroupKickResp, error) {
+	out := new(onebot.SetGroupKickResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SetGroupKick", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SetGroupBan(ctx context.Context, in *onebot.SetGroupBanReq, opts ...grpc.CallOption) (*onebot.SetGroupBanResp, error) {
+	out := new(onebot.SetGroupBanResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SetGroupBan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SetGroupWholeBan(ctx context.Context, in *onebot.SetGroupWholeBanReq, opts ...grpc.CallOption) (*onebot.SetGroupWholeBanResp, error) {
+	out := new(onebot.SetGroupWholeBanResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SetGroupWholeBan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SetGroupCard(ctx context.Context, in *onebot.SetGroupCardReq, opts ...grpc.CallOption) (*onebot.SetGroupCardResp, error) {
+	out := new(onebot.SetGroupCardResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SetGroupCard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SetGroupLeave(ctx context.Context, in *onebot.SetGroupLeaveReq, opts ...grpc.CallOption) (*onebot.SetGroupLeaveResp, error) {
+	out := new(onebot.SetGroupLeaveResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SetGroupLeave", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SetGroupSpecialTitle(ctx context.Context, in *onebot.SetGroupSpecialTitleReq, opts ...grpc.CallOption) (*onebot.SetGroupSpecialTitleResp, error) {
+	out := new(onebot.SetGroupSpecialTitleResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SetGroupSpecialTitle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SetFriendAddRequest(ctx context.Context, in *onebot.SetFriendAddRequestReq, opts ...grpc.CallOption) (*onebot.SetFriendAddRequestResp, error) {
+	out := new(onebot.SetFriendAddRequestResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SetFriendAddRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SetGroupAddRequest(ctx context.Context, in *onebot.SetGroupAddRequestReq, opts ...grpc.CallOption) (*onebot.SetGroupAddRequestResp, error) {
+	out := new(onebot.SetGroupAddRequestResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/SetGroupAddRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetLoginInfo(ctx context.Context, in *onebot.GetLoginInfoReq, opts ...grpc.CallOption) (*onebot.GetLoginInfoResp, error) {
+	out := new(onebot.GetLoginInfoResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetLoginInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetStrangerInfo(ctx context.Context, in *onebot.GetStrangerInfoReq, opts ...grpc.CallOption) (*onebot.GetStrangerInfoResp, error) {
+	out := new(onebot.GetStrangerInfoResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetStrangerInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetFriendList(ctx context.Context, in *onebot.GetFriendListReq, opts ...grpc.CallOption) (*onebot.GetFriendListResp, error) {
+	out := new(onebot.GetFriendListResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetFriendList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetGroupList(ctx context.Context, in *onebot.GetGroupListReq, opts ...grpc.CallOption) (*onebot.GetGroupListResp, error) {
+	out := new(onebot.GetGroupListResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetGroupList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetGroupInfo(ctx context.Context, in *onebot.GetGroupInfoReq, opts ...grpc.CallOption) (*onebot.GetGroupInfoResp, error) {
+	out := new(onebot.GetGroupInfoResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetGroupInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetGroupMemberInfo(ctx context.Context, in *onebot.GetGroupMemberInfoReq, opts ...grpc.CallOption) (*onebot.GetGroupMemberInfoResp, error) {
+	out := new(onebot.GetGroupMemberInfoResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetGroupMemberInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) GetGroupMemberList(ctx context.Context, in *onebot.GetGroupMemberListReq, opts ...grpc.CallOption) (*onebot.GetGroupMemberListResp, error) {
+	out := new(onebot.GetGroupMemberListResp)
+	err := c.cc.Invoke(ctx, "/onebot.OneBot/GetGroupMemberList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oneBotClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsReq, opts ...grpc.CallOption) (OneBot_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OneBot_ServiceDesc.Streams[0], "/onebot.OneBot/SubscribeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oneBotSubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OneBot_SubscribeEventsClient interface {
+	Recv() (*onebot.Frame, error)
+	grpc.ClientStream
+}
+
+type oneBotSubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *oneBotSubscribeEventsClient) Recv() (*onebot.Frame, error) {
+	m := new(onebot.Frame)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// OneBotServer is the server API for OneBot service.
+// All implementations must embed UnimplementedOneBotServer
+// for forward compatibility
+type OneBotServer interface {
+	SendPrivateMsg(context.Context, *onebot.SendPrivateMsgReq) (*onebot.SendPrivateMsgResp, error)
+	SendGroupMsg(context.Context, *onebot.SendGroupMsgReq) (*onebot.SendGroupMsgResp, error)
+	SendMsg(context.Context, *onebot.SendMsgReq) (*onebot.SendMsgResp, error)
+	DeleteMsg(context.Context, *onebot.DeleteMsgReq) (*onebot.DeleteMsgResp, error)
+	GetMsg(context.Context, *onebot.GetMsgReq) (*onebot.GetMsgResp, error)
+	GetForwardMsg(context.Context, *onebot.GetForwardMsgReq) (*onebot.GetForwardMsgResp, error)
+	SetGroupKick(context.Context, *onebot.SetGroupKickReq) (*onebot.SetGroupKickResp, error)
+	SetGroupBan(context.Context, *onebot.SetGroupBanReq) (*onebot.SetGroupBanResp, error)
+	SetGroupWholeBan(context.Context, *onebot.SetGroupWholeBanReq) (*onebot.SetGroupWholeBanResp, error)
+	SetGroupCard(context.Context, *onebot.SetGroupCardReq) (*onebot.SetGroupCardResp, error)
+	SetGroupLeave(context.Context, *onebot.SetGroupLeaveReq) (*onebot.SetGroupLeaveResp, error)
+	SetGroupSpecialTitle(context.Context, *onebot.SetGroupSpecialTitleReq) (*onebot.SetGroupSpecialTitleResp, error)
+	SetFriendAddRequest(context.Context, *onebot.SetFriendAddRequestReq) (*onebot.SetFriendAddRequestResp, error)
+	SetGroupAddRequest(context.Context, *onebot.SetGroupAddRequestReq) (*onebot.SetGroupAddRequestResp, error)
+	GetLoginInfo(context.Context, *onebot.GetLoginInfoReq) (*onebot.GetLoginInfoResp, error)
+	GetStrangerInfo(context.Context, *onebot.GetStrangerInfoReq) (*onebot.GetStrangerInfoResp, error)
+	GetFriendList(context.Context, *onebot.GetFriendListReq) (*onebot.GetFriendListResp, error)
+	GetGroupList(context.Context, *onebot.GetGroupListReq) (*onebot.GetGroupListResp, error)
+	GetGroupInfo(context.Context, *onebot.GetGroupInfoReq) (*onebot.GetGroupInfoResp, error)
+	GetGroupMemberInfo(context.Context, *onebot.GetGroupMemberInfoReq) (*onebot.GetGroupMemberInfoResp, error)
+	GetGroupMemberList(context.Context, *onebot.GetGroupMemberListReq) (*onebot.GetGroupMemberListResp, error)
+	// SubscribeEvents 推送事件帧，bot_ids 为空时推送所有机器人的事件
+	SubscribeEvents(*SubscribeEventsReq, OneBot_SubscribeEventsServer) error
+	mustEmbedUnimplementedOneBotServer()
+}
+
+// UnimplementedOneBotServer must be embedded to have forward compatible implementations.
+type UnimplementedOneBotServer struct {
+}
+
+func (UnimplementedOneBotServer) SendPrivateMsg(context.Context, *onebot.SendPrivateMsgReq) (*onebot.SendPrivateMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendPrivateMsg not implemented")
+}
+func (UnimplementedOneBotServer) SendGroupMsg(context.Context, *onebot.SendGroupMsgReq) (*onebot.SendGroupMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendGroupMsg not implemented")
+}
+func (UnimplementedOneBotServer) SendMsg(context.Context, *onebot.SendMsgReq) (*onebot.SendMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMsg not implemented")
+}
+func (UnimplementedOneBotServer) DeleteMsg(context.Context, *onebot.DeleteMsgReq) (*onebot.DeleteMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMsg not implemented")
+}
+func (UnimplementedOneBotServer) GetMsg(context.Context, *onebot.GetMsgReq) (*onebot.GetMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMsg not implemented")
+}
+func (UnimplementedOneBotServer) GetForwardMsg(context.Context, *onebot.GetForwardMsgReq) (*onebot.GetForwardMsgResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetForwardMsg not implemented")
+}
+func (UnimplementedOneBotServer) SetGroupKick(context.Context, *onebot.SetGroupKickReq) (*onebot.SetGroupKickResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGroupKick not implemented")
+}
+func (UnimplementedOneBotServer) SetGroupBan(context.Context, *onebot.SetGroupBanReq) (*onebot.SetGroupBanResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGroupBan not implemented")
+}
+func (UnimplementedOneBotServer) SetGroupWholeBan(context.Context, *onebot.SetGroupWholeBanReq) (*onebot.SetGroupWholeBanResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGroupWholeBan not implemented")
+}
+func (UnimplementedOneBotServer) SetGroupCard(context.Context, *onebot.SetGroupCardReq) (*onebot.SetGroupCardResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGroupCard not implemented")
+}
+func (UnimplementedOneBotServer) SetGroupLeave(context.Context, *onebot.SetGroupLeaveReq) (*onebot.SetGroupLeaveResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGroupLeave not implemented")
+}
+func (UnimplementedOneBotServer) SetGroupSpecialTitle(context.Context, *onebot.SetGroupSpecialTitleReq) (*onebot.SetGroupSpecialTitleResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGroupSpecialTitle not implemented")
+}
+func (UnimplementedOneBotServer) SetFriendAddRequest(context.Context, *onebot.SetFriendAddRequestReq) (*onebot.SetFriendAddRequestResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFriendAddRequest not implemented")
+}
+func (UnimplementedOneBotServer) SetGroupAddRequest(context.Context, *onebot.SetGroupAddRequestReq) (*onebot.SetGroupAddRequestResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGroupAddRequest not implemented")
+}
+func (UnimplementedOneBotServer) GetLoginInfo(context.Context, *onebot.GetLoginInfoReq) (*onebot.GetLoginInfoResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoginInfo not implemented")
+}
+func (UnimplementedOneBotServer) GetStrangerInfo(context.Context, *onebot.GetStrangerInfoReq) (*onebot.GetStrangerInfoResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStrangerInfo not implemented")
+}
+func (UnimplementedOneBotServer) GetFriendList(context.Context, *onebot.GetFriendListReq) (*onebot.GetFriendListResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFriendList not implemented")
+}
+func (UnimplementedOneBotServer) GetGroupList(context.Context, *onebot.GetGroupListReq) (*onebot.GetGroupListResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupList not implemented")
+}
+func (UnimplementedOneBotServer) GetGroupInfo(context.Context, *onebot.GetGroupInfoReq) (*onebot.GetGroupInfoResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupInfo not implemented")
+}
+func (UnimplementedOneBotServer) GetGroupMemberInfo(context.Context, *onebot.GetGroupMemberInfoReq) (*onebot.GetGroupMemberInfoResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupMemberInfo not implemented")
+}
+func (UnimplementedOneBotServer) GetGroupMemberList(context.Context, *onebot.GetGroupMemberListReq) (*onebot.GetGroupMemberListResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupMemberList not implemented")
+}
+func (UnimplementedOneBotServer) SubscribeEvents(*SubscribeEventsReq, OneBot_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedOneBotServer) mustEmbedUnimplementedOneBotServer() {}
+
+// UnsafeOneBotServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OneBotServer will
+// result in compilation errors.
+type UnsafeOneBotServer interface {
+	mustEmbedUnimplementedOneBotServer()
+}
+
+func RegisterOneBotServer(s grpc.ServiceRegistrar, srv OneBotServer) {
+	s.RegisterService(&OneBot_ServiceDesc, srv)
+}
+
+func _OneBot_SendPrivateMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SendPrivateMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SendPrivateMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SendPrivateMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SendPrivateMsg(ctx, req.(*onebot.SendPrivateMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SendGroupMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SendGroupMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SendGroupMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SendGroupMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SendGroupMsg(ctx, req.(*onebot.SendGroupMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SendMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SendMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SendMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SendMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SendMsg(ctx, req.(*onebot.SendMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_DeleteMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.DeleteMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).DeleteMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/DeleteMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).DeleteMsg(ctx, req.(*onebot.DeleteMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetMsg(ctx, req.(*onebot.GetMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetForwardMsg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetForwardMsgReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetForwardMsg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetForwardMsg",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetForwardMsg(ctx, req.(*onebot.GetForwardMsgReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SetGroupKick_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SetGroupKickReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SetGroupKick(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SetGroupKick",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SetGroupKick(ctx, req.(*onebot.SetGroupKickReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SetGroupBan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SetGroupBanReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SetGroupBan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SetGroupBan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SetGroupBan(ctx, req.(*onebot.SetGroupBanReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SetGroupWholeBan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SetGroupWholeBanReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SetGroupWholeBan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SetGroupWholeBan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SetGroupWholeBan(ctx, req.(*onebot.SetGroupWholeBanReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SetGroupCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SetGroupCardReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SetGroupCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SetGroupCard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SetGroupCard(ctx, req.(*onebot.SetGroupCardReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SetGroupLeave_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SetGroupLeaveReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SetGroupLeave(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SetGroupLeave",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SetGroupLeave(ctx, req.(*onebot.SetGroupLeaveReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SetGroupSpecialTitle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SetGroupSpecialTitleReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SetGroupSpecialTitle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SetGroupSpecialTitle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SetGroupSpecialTitle(ctx, req.(*onebot.SetGroupSpecialTitleReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SetFriendAddRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SetFriendAddRequestReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SetFriendAddRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SetFriendAddRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SetFriendAddRequest(ctx, req.(*onebot.SetFriendAddRequestReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SetGroupAddRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.SetGroupAddRequestReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).SetGroupAddRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/SetGroupAddRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).SetGroupAddRequest(ctx, req.(*onebot.SetGroupAddRequestReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetLoginInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetLoginInfoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetLoginInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetLoginInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetLoginInfo(ctx, req.(*onebot.GetLoginInfoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetStrangerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetStrangerInfoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetStrangerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetStrangerInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetStrangerInfo(ctx, req.(*onebot.GetStrangerInfoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetFriendList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetFriendListReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetFriendList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetFriendList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetFriendList(ctx, req.(*onebot.GetFriendListReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetGroupList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetGroupListReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetGroupList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetGroupList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetGroupList(ctx, req.(*onebot.GetGroupListReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetGroupInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetGroupInfoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetGroupInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetGroupInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetGroupInfo(ctx, req.(*onebot.GetGroupInfoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetGroupMemberInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetGroupMemberInfoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetGroupMemberInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetGroupMemberInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetGroupMemberInfo(ctx, req.(*onebot.GetGroupMemberInfoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_GetGroupMemberList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(onebot.GetGroupMemberListReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OneBotServer).GetGroupMemberList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/onebot.OneBot/GetGroupMemberList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OneBotServer).GetGroupMemberList(ctx, req.(*onebot.GetGroupMemberListReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OneBot_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OneBotServer).SubscribeEvents(m, &oneBotSubscribeEventsServer{stream})
+}
+
+type OneBot_SubscribeEventsServer interface {
+	Send(*onebot.Frame) error
+	grpc.ServerStream
+}
+
+type oneBotSubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *oneBotSubscribeEventsServer) Send(m *onebot.Frame) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// OneBot_ServiceDesc is the grpc.ServiceDesc for OneBot service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OneBot_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "onebot.OneBot",
+	HandlerType: (*OneBotServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendPrivateMsg",
+			Handler:    _OneBot_SendPrivateMsg_Handler,
+		},
+		{
+			MethodName: "SendGroupMsg",
+			Handler:    _OneBot_SendGroupMsg_Handler,
+		},
+		{
+			MethodName: "SendMsg",
+			Handler:    _OneBot_SendMsg_Handler,
+		},
+		{
+			MethodName: "DeleteMsg",
+			Handler:    _OneBot_DeleteMsg_Handler,
+		},
+		{
+			MethodName: "GetMsg",
+			Handler:    _OneBot_GetMsg_Handler,
+		},
+		{
+			MethodName: "GetForwardMsg",
+			Handler:    _OneBot_GetForwardMsg_Handler,
+		},
+		{
+			MethodName: "SetGroupKick",
+			Handler:    _OneBot_SetGroupKick_Handler,
+		},
+		{
+			MethodName: "SetGroupBan",
+			Handler:    _OneBot_SetGroupBan_Handler,
+		},
+		{
+			MethodName: "SetGroupWholeBan",
+			Handler:    _OneBot_SetGroupWholeBan_Handler,
+		},
+		{
+			MethodName: "SetGroupCard",
+			Handler:    _OneBot_SetGroupCard_Handler,
+		},
+		{
+			MethodName: "SetGroupLeave",
+			Handler:    _OneBot_SetGroupLeave_Handler,
+		},
+		{
+			MethodName: "SetGroupSpecialTitle",
+			Handler:    _OneBot_SetGroupSpecialTitle_Handler,
+		},
+		{
+			MethodName: "SetFriendAddRequest",
+			Handler:    _OneBot_SetFriendAddRequest_Handler,
+		},
+		{
+			MethodName: "SetGroupAddRequest",
+			Handler:    _OneBot_SetGroupAddRequest_Handler,
+		},
+		{
+			MethodName: "GetLoginInfo",
+			Handler:    _OneBot_GetLoginInfo_Handler,
+		},
+		{
+			MethodName: "GetStrangerInfo",
+			Handler:    _OneBot_GetStrangerInfo_Handler,
+		},
+		{
+			MethodName: "GetFriendList",
+			Handler:    _OneBot_GetFriendList_Handler,
+		},
+		{
+			MethodName: "GetGroupList",
+			Handler:    _OneBot_GetGroupList_Handler,
+		},
+		{
+			MethodName: "GetGroupInfo",
+			Handler:    _OneBot_GetGroupInfo_Handler,
+		},
+		{
+			MethodName: "GetGroupMemberInfo",
+			Handler:    _OneBot_GetGroupMemberInfo_Handler,
+		},
+		{
+			MethodName: "GetGroupMemberList",
+			Handler:    _OneBot_GetGroupMemberList_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _OneBot_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "onebot_service.proto",
+}
@@ -0,0 +1,153 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// SelfIdKey 指定机器人QQ号的 metadata，与 websocket 连接的 x-self-id 请求头一致
+const SelfIdKey = "x-self-id"
+
+// DefaultEventBuffer 每个订阅者的事件缓冲区大小，缓冲区满时丢弃事件
+const DefaultEventBuffer = 256
+
+type subscriber struct {
+	botIds map[int64]bool
+	events chan *onebot.Frame
+}
+
+// Server 把 OneBot 服务的一元调用映射到 Bot 方法，SubscribeEvents 推送收到的事件
+type Server struct {
+	UnimplementedOneBotServer
+
+	EventBuffer int
+
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+func New() *Server {
+	return &Server{
+		EventBuffer: DefaultEventBuffer,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Install 注册帧拦截器把事件推送给订阅者，只需要调用一次
+func (s *Server) Install() {
+	pbbot.UseFrameInterceptor(func(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+		if frame.FrameType < 300 {
+			s.publish(bot, frame)
+		}
+		next(ctx)
+	})
+}
+
+// Register 在 gRPC 服务器上注册 OneBot 服务
+func (s *Server) Register(server *grpc.Server) {
+	RegisterOneBotServer(server, s)
+}
+
+func (s *Server) publish(bot *pbbot.Bot, frame *onebot.Frame) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subscribers {
+		if len(sub.botIds) > 0 && !sub.botIds[frame.BotId] {
+			continue
+		}
+		select {
+		case sub.events <- frame:
+		default:
+			bot.Logger.Log(pbbot.LevelWarn, "grpc event subscriber is too slow, event dropped", pbbot.F(pbbot.FieldFrameType, frame.FrameType.String()))
+		}
+	}
+}
+
+func (s *Server) subscribe(req *SubscribeEventsReq, stream OneBot_SubscribeEventsServer) error {
+	buffer := s.EventBuffer
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	sub := &subscriber{
+		botIds: make(map[int64]bool, len(req.BotIds)),
+		events: make(chan *onebot.Frame, buffer),
+	}
+	for _, botId := range req.BotIds {
+		sub.botIds[botId] = true
+	}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+	}()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case frame := <-sub.events:
+			if err := stream.Send(frame); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// selectBot 根据 metadata 中的 x-self-id 找到机器人，返回的机器人绑定了请求的 ctx
+func selectBot(ctx context.Context) (*pbbot.Bot, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(SelfIdKey)
+	if len(values) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "missing %s metadata", SelfIdKey)
+	}
+	botId, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s metadata", SelfIdKey)
+	}
+	bot, ok := pbbot.GetBot(botId)
+	if !ok {
+		return nil, status.Errorf(codes.Unavailable, "bot %d offline", botId)
+	}
+	return bot.WithContext(ctx), nil
+}
+
+// checkResp 把 Bot 方法的错误转换为 gRPC 状态码，resp 为空时返回 Internal
+func checkResp(resp interface{}, err error) error {
+	if err != nil {
+		return toStatus(err)
+	}
+	if v := reflect.ValueOf(resp); !v.IsValid() || v.IsNil() {
+		return status.Error(codes.Internal, "empty resp frame")
+	}
+	return nil
+}
+
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, pbbot.ErrDisconnected):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, pbbot.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
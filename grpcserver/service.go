@@ -0,0 +1,282 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ OneBotServer = (*Server)(nil)
+
+func (s *Server) SendPrivateMsg(ctx context.Context, req *onebot.SendPrivateMsgReq) (*onebot.SendPrivateMsgResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Message) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty message")
+	}
+	resp, err := bot.SendPrivateMessage(req.UserId, pbbot.ParseMsg(req.Message), req.AutoEscape)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SendGroupMsg(ctx context.Context, req *onebot.SendGroupMsgReq) (*onebot.SendGroupMsgResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Message) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty message")
+	}
+	resp, err := bot.SendGroupMessage(req.GroupId, pbbot.ParseMsg(req.Message), req.AutoEscape)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SendMsg(ctx context.Context, req *onebot.SendMsgReq) (*onebot.SendMsgResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Message) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty message")
+	}
+	if req.MessageType != pbbot.MessageTypePrivate && req.MessageType != pbbot.MessageTypeGroup {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid message_type %s", req.MessageType)
+	}
+	target := pbbot.Target{MessageType: req.MessageType, UserId: req.UserId, GroupId: req.GroupId}
+	resp, err := bot.Send(bot.Context(), target, pbbot.ParseMsg(req.Message))
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) DeleteMsg(ctx context.Context, req *onebot.DeleteMsgReq) (*onebot.DeleteMsgResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.DeleteMsg(req.MessageId)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetMsg(ctx context.Context, req *onebot.GetMsgReq) (*onebot.GetMsgResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetMsg(req.MessageId)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetForwardMsg(ctx context.Context, req *onebot.GetForwardMsgReq) (*onebot.GetForwardMsgResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetForwardMsg(req.Id)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SetGroupKick(ctx context.Context, req *onebot.SetGroupKickReq) (*onebot.SetGroupKickResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.SetGroupKick(req.GroupId, req.UserId, req.RejectAddRequest)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SetGroupBan(ctx context.Context, req *onebot.SetGroupBanReq) (*onebot.SetGroupBanResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.SetGroupBan(req.GroupId, req.UserId, req.Duration)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SetGroupWholeBan(ctx context.Context, req *onebot.SetGroupWholeBanReq) (*onebot.SetGroupWholeBanResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.SetGroupWholeBan(req.GroupId, req.Enable)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SetGroupCard(ctx context.Context, req *onebot.SetGroupCardReq) (*onebot.SetGroupCardResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.SetGroupCard(req.GroupId, req.UserId, req.Card)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SetGroupLeave(ctx context.Context, req *onebot.SetGroupLeaveReq) (*onebot.SetGroupLeaveResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.SetGroupLeave(req.GroupId, req.IsDismiss)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SetGroupSpecialTitle(ctx context.Context, req *onebot.SetGroupSpecialTitleReq) (*onebot.SetGroupSpecialTitleResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.SetGroupSpecialTitle(req.GroupId, req.UserId, req.SpecialTitle, req.Duration)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SetFriendAddRequest(ctx context.Context, req *onebot.SetFriendAddRequestReq) (*onebot.SetFriendAddRequestResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.SetFriendAddRequest(req.Flag, req.Approve, req.Remark)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) SetGroupAddRequest(ctx context.Context, req *onebot.SetGroupAddRequestReq) (*onebot.SetGroupAddRequestResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.SetGroupAddRequestWithSubType(req.Flag, req.SubType, req.Approve, req.Reason)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetLoginInfo(ctx context.Context, req *onebot.GetLoginInfoReq) (*onebot.GetLoginInfoResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetLoginInfo()
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetStrangerInfo(ctx context.Context, req *onebot.GetStrangerInfoReq) (*onebot.GetStrangerInfoResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetStrangerInfo(req.UserId, req.NoCache)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetFriendList(ctx context.Context, req *onebot.GetFriendListReq) (*onebot.GetFriendListResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetFriendList()
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetGroupList(ctx context.Context, req *onebot.GetGroupListReq) (*onebot.GetGroupListResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetGroupList()
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetGroupInfo(ctx context.Context, req *onebot.GetGroupInfoReq) (*onebot.GetGroupInfoResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetGroupInfo(req.GroupId, req.NoCache)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetGroupMemberInfo(ctx context.Context, req *onebot.GetGroupMemberInfoReq) (*onebot.GetGroupMemberInfoResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetGroupMemberInfo(req.GroupId, req.UserId, req.NoCache)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Server) GetGroupMemberList(ctx context.Context, req *onebot.GetGroupMemberListReq) (*onebot.GetGroupMemberListResp, error) {
+	bot, err := selectBot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.GetGroupMemberList(req.GroupId)
+	if err := checkResp(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SubscribeEvents 推送收到的事件帧，直到客户端断开
+func (s *Server) SubscribeEvents(req *SubscribeEventsReq, stream OneBot_SubscribeEventsServer) error {
+	return s.subscribe(req, stream)
+}
@@ -24,6 +24,11 @@ var HandleGroupMessage = func(bot *Bot, event *onebot.GroupMessageEvent) {
 
 }
 
+// HandleMessage 收到私聊或群聊消息，在 HandlePrivateMessage 或 HandleGroupMessage 之后调用
+var HandleMessage = func(ctx *MessageContext) {
+
+}
+
 // HandleGroupUploadNotice 有人上传群文件
 var HandleGroupUploadNotice = func(bot *Bot, event *onebot.GroupUploadNoticeEvent) {
 
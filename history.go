@@ -0,0 +1,205 @@
+package pbbot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// MessageHistory 不为 nil 时，收到和发出的消息都会被记录，撤回事件会标记对应的记录
+var MessageHistory HistoryStore
+
+// HistoryRecord 一条消息记录，Target 为消息所在的会话，私聊时为对方
+type HistoryRecord struct {
+	BotId      int64             `json:"bot_id"`
+	MessageId  int32             `json:"message_id"`
+	Target     Target            `json:"target"`
+	SenderId   int64             `json:"sender_id"`
+	Time       int64             `json:"time"`
+	Message    []*onebot.Message `json:"message"`
+	RawMessage string            `json:"raw_message,omitempty"`
+	Outgoing   bool              `json:"outgoing,omitempty"`
+	Recalled   bool              `json:"recalled,omitempty"`
+	OperatorId int64             `json:"operator_id,omitempty"`
+}
+
+// HistoryStore 消息记录存储，List 类方法按时间从新到旧返回，limit 为 0 表示不限制
+type HistoryStore interface {
+	Save(record *HistoryRecord) error
+	Get(botId int64, messageId int32) (*HistoryRecord, bool)
+	ListByChat(botId int64, target Target, limit int) []*HistoryRecord
+	ListByUser(botId int64, userId int64, limit int) []*HistoryRecord
+	MarkRecalled(botId int64, messageId int32, operatorId int64) error
+}
+
+type historyKey struct {
+	botId     int64
+	messageId int32
+}
+
+// MemoryHistoryStore 内存环形缓冲区，超过容量时覆盖最旧的记录
+type MemoryHistoryStore struct {
+	mu      sync.RWMutex
+	records []*HistoryRecord
+	next    int
+	index   map[historyKey]*HistoryRecord
+}
+
+func NewMemoryHistoryStore(capacity int) *MemoryHistoryStore {
+	return &MemoryHistoryStore{
+		records: make([]*HistoryRecord, capacity),
+		index:   make(map[historyKey]*HistoryRecord),
+	}
+}
+
+func (s *MemoryHistoryStore) Save(record *HistoryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.records) == 0 {
+		return nil
+	}
+	if old := s.records[s.next]; old != nil {
+		key := historyKey{old.BotId, old.MessageId}
+		if s.index[key] == old {
+			delete(s.index, key)
+		}
+	}
+	s.records[s.next] = record
+	s.index[historyKey{record.BotId, record.MessageId}] = record
+	s.next = (s.next + 1) % len(s.records)
+	return nil
+}
+
+func (s *MemoryHistoryStore) Get(botId int64, messageId int32) (*HistoryRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.index[historyKey{botId, messageId}]
+	if !ok {
+		return nil, false
+	}
+	result := *record
+	return &result, true
+}
+
+func (s *MemoryHistoryStore) ListByChat(botId int64, target Target, limit int) []*HistoryRecord {
+	return s.list(limit, func(record *HistoryRecord) bool {
+		return record.BotId == botId && sameChat(record.Target, target)
+	})
+}
+
+func (s *MemoryHistoryStore) ListByUser(botId int64, userId int64, limit int) []*HistoryRecord {
+	return s.list(limit, func(record *HistoryRecord) bool {
+		return record.BotId == botId && record.SenderId == userId
+	})
+}
+
+func (s *MemoryHistoryStore) MarkRecalled(botId int64, messageId int32, operatorId int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.index[historyKey{botId, messageId}]; ok {
+		record.Recalled = true
+		record.OperatorId = operatorId
+	}
+	return nil
+}
+
+// All 按时间从旧到新返回所有记录
+func (s *MemoryHistoryStore) All() []*HistoryRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*HistoryRecord, 0, len(s.index))
+	for i := 0; i < len(s.records); i++ {
+		record := s.records[(s.next+i)%len(s.records)]
+		if record != nil {
+			copied := *record
+			result = append(result, &copied)
+		}
+	}
+	return result
+}
+
+func (s *MemoryHistoryStore) list(limit int, match func(record *HistoryRecord) bool) []*HistoryRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*HistoryRecord, 0)
+	for i := 1; i <= len(s.records); i++ {
+		record := s.records[(s.next-i+len(s.records))%len(s.records)]
+		if record == nil {
+			break
+		}
+		if !match(record) {
+			continue
+		}
+		copied := *record
+		result = append(result, &copied)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+func sameChat(a Target, b Target) bool {
+	if a.MessageType != b.MessageType {
+		return false
+	}
+	if a.MessageType == MessageTypeGroup {
+		return a.GroupId == b.GroupId
+	}
+	return a.UserId == b.UserId
+}
+
+// recordHistory 记录收到的消息和撤回事件
+func (bot *Bot) recordHistory(frame *onebot.Frame) {
+	var err error
+	if event := frame.GetPrivateMessageEvent(); event != nil {
+		err = MessageHistory.Save(&HistoryRecord{
+			BotId:      bot.BotId,
+			MessageId:  event.MessageId,
+			Target:     User(event.UserId),
+			SenderId:   event.UserId,
+			Time:       event.Time,
+			Message:    event.Message,
+			RawMessage: event.RawMessage,
+		})
+	} else if event := frame.GetGroupMessageEvent(); event != nil {
+		err = MessageHistory.Save(&HistoryRecord{
+			BotId:      bot.BotId,
+			MessageId:  event.MessageId,
+			Target:     Group(event.GroupId),
+			SenderId:   event.UserId,
+			Time:       event.Time,
+			Message:    event.Message,
+			RawMessage: event.RawMessage,
+		})
+	} else if event := frame.GetGroupRecallNoticeEvent(); event != nil {
+		err = MessageHistory.MarkRecalled(bot.BotId, event.MessageId, event.OperatorId)
+	} else if event := frame.GetFriendRecallNoticeEvent(); event != nil {
+		err = MessageHistory.MarkRecalled(bot.BotId, event.MessageId, event.UserId)
+	}
+	if err != nil {
+		bot.Logger.Log(LevelError, "failed to record message history", Err(err))
+	}
+}
+
+// recordSent 记录机器人发出的消息
+func (bot *Bot) recordSent(target Target, msg *Msg, messageId int32) {
+	if MessageHistory == nil || messageId == 0 {
+		return
+	}
+	if target.MessageType == MessageTypePrivate {
+		target.GroupId = 0
+	}
+	if err := MessageHistory.Save(&HistoryRecord{
+		BotId:     bot.BotId,
+		MessageId: messageId,
+		Target:    target,
+		SenderId:  bot.BotId,
+		Time:      time.Now().Unix(),
+		Message:   msg.MessageList,
+		Outgoing:  true,
+	}); err != nil {
+		bot.Logger.Log(LevelError, "failed to record sent message", Err(err), F(FieldGroupId, target.GroupId), F(FieldUserId, target.UserId))
+	}
+}
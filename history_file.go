@@ -0,0 +1,176 @@
+package pbbot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+var ErrInvalidHistoryCapacity = errors.New("history capacity must be positive")
+
+// FileHistoryStore 在 MemoryHistoryStore 的基础上把记录追加写入文件，启动时从文件恢复，文件过大时自动压缩
+type FileHistoryStore struct {
+	*MemoryHistoryStore
+
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	lines    int
+	capacity int
+}
+
+type historyFileLine struct {
+	Record *HistoryRecord `json:"record,omitempty"`
+	Recall *historyRecall `json:"recall,omitempty"`
+}
+
+type historyRecall struct {
+	BotId      int64 `json:"bot_id"`
+	MessageId  int32 `json:"message_id"`
+	OperatorId int64 `json:"operator_id"`
+}
+
+// NewFileHistoryStore capacity 为内存中保留的记录数，必须大于 0
+func NewFileHistoryStore(path string, capacity int) (*FileHistoryStore, error) {
+	if capacity <= 0 {
+		return nil, ErrInvalidHistoryCapacity
+	}
+	s := &FileHistoryStore{
+		MemoryHistoryStore: NewMemoryHistoryStore(capacity),
+		path:               path,
+		capacity:           capacity,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save 内存更新和追加写入在同一把锁内完成，避免与压缩交错导致文件中出现重复记录
+func (s *FileHistoryStore) Save(record *HistoryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.MemoryHistoryStore.Save(record); err != nil {
+		return err
+	}
+	return s.appendLocked(&historyFileLine{Record: record})
+}
+
+func (s *FileHistoryStore) MarkRecalled(botId int64, messageId int32, operatorId int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.MemoryHistoryStore.MarkRecalled(botId, messageId, operatorId); err != nil {
+		return err
+	}
+	return s.appendLocked(&historyFileLine{Recall: &historyRecall{
+		BotId:      botId,
+		MessageId:  messageId,
+		OperatorId: operatorId,
+	}})
+}
+
+func (s *FileHistoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func (s *FileHistoryStore) appendLocked(line *historyFileLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	s.lines++
+	if s.lines > 2*s.capacity {
+		return s.compactLocked()
+	}
+	return nil
+}
+
+func (s *FileHistoryStore) load() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line historyFileLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			// 忽略写入中断导致的不完整行
+			continue
+		}
+		if line.Record != nil {
+			_ = s.MemoryHistoryStore.Save(line.Record)
+		}
+		if line.Recall != nil {
+			_ = s.MemoryHistoryStore.MarkRecalled(line.Recall.BotId, line.Recall.MessageId, line.Recall.OperatorId)
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *FileHistoryStore) compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compactLocked()
+}
+
+// compactLocked 把内存中的记录重写到新文件，替换原文件，写入失败时保留原文件
+func (s *FileHistoryStore) compactLocked() error {
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	abort := func(err error) error {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	records := s.MemoryHistoryStore.All()
+	for _, record := range records {
+		data, err := json.Marshal(&historyFileLine{Record: record})
+		if err != nil {
+			return abort(err)
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return abort(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return abort(err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if s.file != nil {
+		_ = s.file.Close()
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		_ = os.Remove(tmpPath)
+		// 原文件已经关闭，重新打开以便之后继续追加写入
+		s.file, _ = os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		return err
+	}
+	s.file, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.lines = len(records)
+	return nil
+}
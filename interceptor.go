@@ -0,0 +1,69 @@
+package pbbot
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// FrameInterceptor 包装收到的每一帧的处理，包括事件和API响应，不调用 next 时丢弃该帧
+type FrameInterceptor func(ctx context.Context, bot *Bot, frame *onebot.Frame, next func(ctx context.Context))
+
+// ApiInterceptor 包装每次API调用，req 为已经设置 echo 的请求帧
+type ApiInterceptor func(ctx context.Context, bot *Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error)
+
+var (
+	interceptorsLock  sync.RWMutex
+	frameInterceptors []FrameInterceptor
+	apiInterceptors   []ApiInterceptor
+)
+
+// UseFrameInterceptor 添加帧拦截器，按添加顺序由外向内执行
+func UseFrameInterceptor(interceptor ...FrameInterceptor) {
+	interceptorsLock.Lock()
+	defer interceptorsLock.Unlock()
+	frameInterceptors = append(frameInterceptors, interceptor...)
+}
+
+// UseApiInterceptor 添加API拦截器，按添加顺序由外向内执行
+func UseApiInterceptor(interceptor ...ApiInterceptor) {
+	interceptorsLock.Lock()
+	defer interceptorsLock.Unlock()
+	apiInterceptors = append(apiInterceptors, interceptor...)
+}
+
+func interceptFrame(ctx context.Context, bot *Bot, frame *onebot.Frame, handle func(ctx context.Context)) {
+	interceptorsLock.RLock()
+	chain := frameInterceptors
+	interceptorsLock.RUnlock()
+
+	var next func(i int, ctx context.Context)
+	next = func(i int, ctx context.Context) {
+		if i < len(chain) {
+			chain[i](ctx, bot, frame, func(ctx context.Context) {
+				next(i+1, ctx)
+			})
+			return
+		}
+		handle(ctx)
+	}
+	next(0, ctx)
+}
+
+func interceptApi(ctx context.Context, bot *Bot, req *onebot.Frame, invoke func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+	interceptorsLock.RLock()
+	chain := apiInterceptors
+	interceptorsLock.RUnlock()
+
+	var next func(i int, ctx context.Context) (*onebot.Frame, error)
+	next = func(i int, ctx context.Context) (*onebot.Frame, error) {
+		if i < len(chain) {
+			return chain[i](ctx, bot, req, func(ctx context.Context) (*onebot.Frame, error) {
+				return next(i+1, ctx)
+			})
+		}
+		return invoke(ctx)
+	}
+	return next(0, ctx)
+}
@@ -0,0 +1,108 @@
+package pbbot
+
+import (
+	"github.com/ProtobufBot/go-pbbot/util"
+	"github.com/sirupsen/logrus"
+)
+
+// Level 日志等级，LevelTrace 用于记录每一帧的收发
+type Level int
+
+const (
+	LevelTrace Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// 常用的结构化字段名
+const (
+	FieldBotId     = "bot_id"
+	FieldEcho      = "echo"
+	FieldFrameType = "frame_type"
+	FieldGroupId   = "group_id"
+	FieldUserId    = "user_id"
+	FieldError     = "error"
+)
+
+// Field 结构化日志字段
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F 构造日志字段
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err 构造错误字段
+func Err(err error) Field {
+	return Field{Key: FieldError, Value: err}
+}
+
+// Logger 日志接口，可以通过 DefaultLogger 全局替换，也可以通过 Server.Logger 或 NewBotWithLogger 为每个机器人单独设置
+type Logger interface {
+	Enabled(level Level) bool
+	Log(level Level, msg string, fields ...Field)
+	// With 返回带有固定字段的 Logger
+	With(fields ...Field) Logger
+}
+
+// DefaultLogger 默认日志，使用 logrus 标准实例
+var DefaultLogger Logger = NewLogrusLogger(logrus.StandardLogger())
+
+type logrusLogger struct {
+	entry *logrus.Entry
+}
+
+// NewLogrusLogger 把 logrus.Logger 适配为 Logger
+func NewLogrusLogger(logger *logrus.Logger) Logger {
+	return &logrusLogger{entry: logrus.NewEntry(logger)}
+}
+
+func (l *logrusLogger) Enabled(level Level) bool {
+	return l.entry.Logger.IsLevelEnabled(logrusLevel(level))
+}
+
+func (l *logrusLogger) Log(level Level, msg string, fields ...Field) {
+	entry := l.entry
+	if len(fields) > 0 {
+		entry = entry.WithFields(logrusFields(fields))
+	}
+	entry.Log(logrusLevel(level), msg)
+}
+
+func (l *logrusLogger) With(fields ...Field) Logger {
+	return &logrusLogger{entry: l.entry.WithFields(logrusFields(fields))}
+}
+
+func logrusLevel(level Level) logrus.Level {
+	switch level {
+	case LevelTrace:
+		return logrus.TraceLevel
+	case LevelDebug:
+		return logrus.DebugLevel
+	case LevelInfo:
+		return logrus.InfoLevel
+	case LevelWarn:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
+
+func logrusFields(fields []Field) logrus.Fields {
+	result := make(logrus.Fields, len(fields))
+	for _, field := range fields {
+		result[field.Key] = field.Value
+	}
+	return result
+}
+
+func init() {
+	util.HandlePanic = func(e interface{}, stack []byte) {
+		DefaultLogger.Log(LevelError, "err recovered", F("panic", e), F("stack", string(stack)))
+	}
+}
@@ -0,0 +1,66 @@
+//go:build go1.21
+// +build go1.21
+
+package pbbot
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLevelTrace slog 没有 trace 等级，使用比 debug 更低的等级
+const SlogLevelTrace = slog.LevelDebug - 4
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger 把 slog.Logger 适配为 Logger
+//
+// log/slog 从 Go 1.21 开始提供，本文件只在 Go 1.21 及以上版本编译，go.mod 中的 go 1.16 不会因此提高
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Enabled(level Level) bool {
+	return l.logger.Enabled(context.Background(), slogLevel(level))
+}
+
+func (l *slogLogger) Log(level Level, msg string, fields ...Field) {
+	l.logger.LogAttrs(context.Background(), slogLevel(level), msg, slogAttrs(fields)...)
+}
+
+func (l *slogLogger) With(fields ...Field) Logger {
+	args := make([]interface{}, 0, len(fields))
+	for _, attr := range slogAttrs(fields) {
+		args = append(args, attr)
+	}
+	return &slogLogger{logger: l.logger.With(args...)}
+}
+
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case LevelTrace:
+		return SlogLevelTrace
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+func slogAttrs(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		if err, ok := field.Value.(error); ok {
+			attrs = append(attrs, slog.String(field.Key, err.Error()))
+			continue
+		}
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	return attrs
+}
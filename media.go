@@ -0,0 +1,166 @@
+package pbbot
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+const (
+	ImageTypeFlash = "flash" // 闪照
+	ImageTypeShow  = "show"  // 秀图
+)
+
+var (
+	MaxImageSize  = 30 * 1024 * 1024 // ImageBytes、ImageFile 允许的最大字节数
+	MaxRecordSize = 10 * 1024 * 1024 // RecordBytes、RecordFile 允许的最大字节数
+
+	// DefaultMediaServer 不为 nil 时，ImageBytes 等方法通过临时 HTTP 地址发送文件，而不是 base64:// 或 file://
+	DefaultMediaServer *MediaServer
+)
+
+// MediaOption 图片、语音、短视频消息段的可选参数
+type MediaOption func(data map[string]string)
+
+// WithFile 设置 file 字段，可以是文件名、绝对路径、网络URL或 base64 编码
+func WithFile(file string) MediaOption {
+	return func(data map[string]string) {
+		data["file"] = file
+	}
+}
+
+// WithImageType 设置图片类型，ImageTypeFlash 或 ImageTypeShow
+func WithImageType(imageType string) MediaOption {
+	return func(data map[string]string) {
+		data["type"] = imageType
+	}
+}
+
+// WithMagic 语音变声
+func WithMagic(magic bool) MediaOption {
+	return func(data map[string]string) {
+		data["magic"] = boolData(magic)
+	}
+}
+
+// WithCache 网络文件是否使用已缓存的文件
+func WithCache(cache bool) MediaOption {
+	return func(data map[string]string) {
+		data["cache"] = boolData(cache)
+	}
+}
+
+// WithProxy 网络文件是否通过代理下载
+func WithProxy(proxy bool) MediaOption {
+	return func(data map[string]string) {
+		data["proxy"] = boolData(proxy)
+	}
+}
+
+// WithTimeout 网络文件下载超时时间，单位秒
+func WithTimeout(timeout int) MediaOption {
+	return func(data map[string]string) {
+		data["timeout"] = strconv.Itoa(timeout)
+	}
+}
+
+func newMediaMessage(messageType string, file string, options []MediaOption) *onebot.Message {
+	data := map[string]string{
+		"file": file,
+	}
+	for _, option := range options {
+		option(data)
+	}
+	return &onebot.Message{
+		Type: messageType,
+		Data: data,
+	}
+}
+
+func boolData(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// ImageBytes 内存中的图片，编码为 base64://，超过 MaxImageSize 时记录错误
+func (msg *Msg) ImageBytes(data []byte, options ...MediaOption) *Msg {
+	file, err := encodeBytes(data, MaxImageSize)
+	if err != nil {
+		return msg.setErr(fmt.Errorf("image: %w", err))
+	}
+	msg.MessageList = append(msg.MessageList, newMediaMessage("image", file, options))
+	return msg
+}
+
+// ImageFile 本地图片，编码为 file://，超过 MaxImageSize 时记录错误
+func (msg *Msg) ImageFile(filePath string, options ...MediaOption) *Msg {
+	file, err := encodeFile(filePath, MaxImageSize)
+	if err != nil {
+		return msg.setErr(fmt.Errorf("image: %w", err))
+	}
+	msg.MessageList = append(msg.MessageList, newMediaMessage("image", file, options))
+	return msg
+}
+
+// RecordBytes 内存中的语音，编码为 base64://，超过 MaxRecordSize 时记录错误
+func (msg *Msg) RecordBytes(data []byte, options ...MediaOption) *Msg {
+	file, err := encodeBytes(data, MaxRecordSize)
+	if err != nil {
+		return msg.setErr(fmt.Errorf("record: %w", err))
+	}
+	return msg.Record(file, options...)
+}
+
+// RecordFile 本地语音，编码为 file://，超过 MaxRecordSize 时记录错误
+func (msg *Msg) RecordFile(filePath string, options ...MediaOption) *Msg {
+	file, err := encodeFile(filePath, MaxRecordSize)
+	if err != nil {
+		return msg.setErr(fmt.Errorf("record: %w", err))
+	}
+	return msg.Record(file, options...)
+}
+
+func encodeBytes(data []byte, maxSize int) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("empty data")
+	}
+	if len(data) > maxSize {
+		return "", fmt.Errorf("size %d exceeds limit %d", len(data), maxSize)
+	}
+	if DefaultMediaServer != nil {
+		return DefaultMediaServer.Put(data), nil
+	}
+	return "base64://" + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func encodeFile(filePath string, maxSize int) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", absPath)
+	}
+	if info.Size() > int64(maxSize) {
+		return "", fmt.Errorf("size %d exceeds limit %d", info.Size(), maxSize)
+	}
+	if DefaultMediaServer != nil {
+		data, err := ioutil.ReadFile(absPath)
+		if err != nil {
+			return "", err
+		}
+		return DefaultMediaServer.Put(data), nil
+	}
+	return "file://" + filepath.ToSlash(absPath), nil
+}
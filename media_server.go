@@ -0,0 +1,100 @@
+package pbbot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MediaServer 临时文件服务，用于只支持网络URL的实现，需要挂载到 http 路由上
+type MediaServer struct {
+	BaseUrl string
+	TTL     time.Duration
+
+	mu       sync.Mutex
+	files    map[string]*mediaFile
+	sweeping bool
+}
+
+type mediaFile struct {
+	data        []byte
+	contentType string
+	expireAt    time.Time
+}
+
+// NewMediaServer baseUrl 是挂载路径对外的访问地址，例如 http://1.2.3.4:8081/media
+func NewMediaServer(baseUrl string, ttl time.Duration) *MediaServer {
+	return &MediaServer{
+		BaseUrl: strings.TrimSuffix(baseUrl, "/"),
+		TTL:     ttl,
+		files:   make(map[string]*mediaFile),
+	}
+}
+
+// Put 保存文件并返回访问地址，文件在 TTL 之后过期并从内存中删除
+func (s *MediaServer) Put(data []byte) string {
+	id := randomId()
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[id] = &mediaFile{
+		data:        data,
+		contentType: http.DetectContentType(data),
+		expireAt:    now.Add(s.TTL),
+	}
+	if !s.sweeping {
+		s.sweeping = true
+		time.AfterFunc(s.TTL, s.sweep)
+	}
+	return s.BaseUrl + "/" + id
+}
+
+// Len 保存的文件数量，包括已过期但还没有删除的文件
+func (s *MediaServer) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files)
+}
+
+// sweep 删除过期的文件，还有文件时在最早的过期时间再次执行，没有文件时不占用定时器
+func (s *MediaServer) sweep() {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for k, f := range s.files {
+		if now.After(f.expireAt) {
+			delete(s.files, k)
+		} else if next.IsZero() || f.expireAt.Before(next) {
+			next = f.expireAt
+		}
+	}
+	if len(s.files) == 0 {
+		s.sweeping = false
+		return
+	}
+	time.AfterFunc(next.Sub(now)+time.Millisecond, s.sweep)
+}
+
+func (s *MediaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := path.Base(r.URL.Path)
+	s.mu.Lock()
+	f, ok := s.files[id]
+	s.mu.Unlock()
+	if !ok || time.Now().After(f.expireAt) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", f.contentType)
+	_, _ = w.Write(f.data)
+}
+
+func randomId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics go-pbbot 的 Prometheus 指标，计数通过拦截器收集，连接数和队列长度在抓取时读取
+type Metrics struct {
+	frames          *prometheus.CounterVec
+	apiCalls        *prometheus.CounterVec
+	apiErrors       *prometheus.CounterVec
+	apiLatency      *prometheus.HistogramVec
+	handlerDuration *prometheus.HistogramVec
+	handlerPanics   *prometheus.CounterVec
+
+	connectedBots *prometheus.Desc
+	pendingCalls  *prometheus.Desc
+	sendQueue     *prometheus.Desc
+	rateWaiting   *prometheus.Desc
+}
+
+// New 创建指标，namespace 为空时使用 pbbot
+func New(namespace string) *Metrics {
+	if namespace == "" {
+		namespace = "pbbot"
+	}
+	return &Metrics{
+		frames: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "frames_received_total",
+			Help:      "Inbound frames by frame type.",
+		}, []string{"frame_type"}),
+		apiCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_calls_total",
+			Help:      "API calls by request frame type.",
+		}, []string{"frame_type"}),
+		apiErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_errors_total",
+			Help:      "Failed API calls by request frame type and reason.",
+		}, []string{"frame_type", "reason"}),
+		apiLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_call_duration_seconds",
+			Help:      "API call latency including rate limiting by request frame type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"frame_type"}),
+		handlerDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "handler_duration_seconds",
+			Help:      "Inbound frame handling duration by frame type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"frame_type"}),
+		handlerPanics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "handler_panics_total",
+			Help:      "Panics while handling inbound frames by frame type.",
+		}, []string{"frame_type"}),
+		connectedBots: prometheus.NewDesc(namespace+"_connected_bots", "Connected bots.", nil, nil),
+		pendingCalls:  prometheus.NewDesc(namespace+"_pending_api_calls", "API calls waiting for a response frame.", []string{"bot_id"}, nil),
+		sendQueue:     prometheus.NewDesc(namespace+"_send_queue_depth", "Messages waiting in the websocket send queue.", []string{"bot_id"}, nil),
+		rateWaiting:   prometheus.NewDesc(namespace+"_rate_limit_waiting", "API calls waiting for the rate limiter.", []string{"bot_id"}, nil),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.frames.Describe(ch)
+	m.apiCalls.Describe(ch)
+	m.apiErrors.Describe(ch)
+	m.apiLatency.Describe(ch)
+	m.handlerDuration.Describe(ch)
+	m.handlerPanics.Describe(ch)
+	ch <- m.connectedBots
+	ch <- m.pendingCalls
+	ch <- m.sendQueue
+	ch <- m.rateWaiting
+}
+
+// Collect 实现 prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.frames.Collect(ch)
+	m.apiCalls.Collect(ch)
+	m.apiErrors.Collect(ch)
+	m.apiLatency.Collect(ch)
+	m.handlerDuration.Collect(ch)
+	m.handlerPanics.Collect(ch)
+
+	bots := pbbot.ListBots()
+	ch <- prometheus.MustNewConstMetric(m.connectedBots, prometheus.GaugeValue, float64(len(bots)))
+	for _, bot := range bots {
+		botId := strconv.FormatInt(bot.BotId, 10)
+		ch <- prometheus.MustNewConstMetric(m.pendingCalls, prometheus.GaugeValue, float64(bot.PendingCalls()), botId)
+		ch <- prometheus.MustNewConstMetric(m.sendQueue, prometheus.GaugeValue, float64(bot.Session.QueueDepth()), botId)
+		if bot.RateLimiter != nil {
+			ch <- prometheus.MustNewConstMetric(m.rateWaiting, prometheus.GaugeValue, float64(bot.RateLimiter.Stats().Waiting), botId)
+		}
+	}
+}
+
+// Install 注册帧和API拦截器，只需要调用一次
+func (m *Metrics) Install() {
+	pbbot.UseFrameInterceptor(m.interceptFrame)
+	pbbot.UseApiInterceptor(m.interceptApi)
+}
+
+func (m *Metrics) interceptFrame(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+	frameType := frame.FrameType.String()
+	m.frames.WithLabelValues(frameType).Inc()
+	start := time.Now()
+	defer func() {
+		m.handlerDuration.WithLabelValues(frameType).Observe(time.Since(start).Seconds())
+		if e := recover(); e != nil {
+			m.handlerPanics.WithLabelValues(frameType).Inc()
+			panic(e)
+		}
+	}()
+	next(ctx)
+}
+
+func (m *Metrics) interceptApi(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+	frameType := req.FrameType.String()
+	m.apiCalls.WithLabelValues(frameType).Inc()
+	start := time.Now()
+	resp, err := next(ctx)
+	m.apiLatency.WithLabelValues(frameType).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.apiErrors.WithLabelValues(frameType, errorReason(err)).Inc()
+	} else if !resp.Ok {
+		m.apiErrors.WithLabelValues(frameType, "failed").Inc()
+	}
+	return resp, err
+}
+
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, pbbot.ErrTimeout):
+		return "timeout"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "cancelled"
+	default:
+		return "error"
+	}
+}
+
+// Register 创建指标、注册到 reg 并安装拦截器
+func Register(reg prometheus.Registerer) (*Metrics, error) {
+	m := New("")
+	if err := reg.Register(m); err != nil {
+		return nil, err
+	}
+	m.Install()
+	return m, nil
+}
+
+// Handler 指标的 HTTP 处理函数，gatherer 为 nil 时使用 prometheus.DefaultGatherer
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,53 @@
+package pbbot
+
+import (
+	"sync"
+)
+
+// MessageMiddleware 消息中间件，在 HandlePrivateMessage、HandleGroupMessage、HandleMessage 和命令之前执行，不调用 next 时丢弃该消息
+type MessageMiddleware func(ctx *MessageContext, next func())
+
+var (
+	middlewaresLock sync.RWMutex
+	middlewares     []MessageMiddleware
+	messageHandlers []func(ctx *MessageContext)
+)
+
+// UseMessageMiddleware 添加消息中间件，按添加顺序执行
+func UseMessageMiddleware(middleware ...MessageMiddleware) {
+	middlewaresLock.Lock()
+	defer middlewaresLock.Unlock()
+	middlewares = append(middlewares, middleware...)
+}
+
+// UseMessageHandler 添加消息处理函数，在所有中间件通过并匹配命令之后按添加顺序执行，被中间件丢弃的消息不会调用
+func UseMessageHandler(handler ...func(ctx *MessageContext)) {
+	middlewaresLock.Lock()
+	defer middlewaresLock.Unlock()
+	messageHandlers = append(messageHandlers, handler...)
+}
+
+// dispatchMessage 依次执行中间件，全部通过后调用 handle、HandleMessage，匹配命令后执行 UseMessageHandler 添加的处理函数
+func dispatchMessage(ctx *MessageContext, handle func()) {
+	middlewaresLock.RLock()
+	chain := middlewares
+	handlers := messageHandlers
+	middlewaresLock.RUnlock()
+
+	var next func(i int)
+	next = func(i int) {
+		if i < len(chain) {
+			chain[i](ctx, func() {
+				next(i + 1)
+			})
+			return
+		}
+		handle()
+		HandleMessage(ctx)
+		dispatchCommand(ctx)
+		for _, handler := range handlers {
+			handler(ctx)
+		}
+	}
+	next(0)
+}
@@ -2,12 +2,16 @@ package pbbot
 
 import (
 	"strconv"
+	"strings"
 
+	"github.com/ProtobufBot/go-pbbot/cqcode"
 	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
 )
 
 type Msg struct {
 	MessageList []*onebot.Message
+
+	err error
 }
 
 func NewMsg() *Msg {
@@ -36,12 +40,17 @@ func (msg *Msg) Face(id int) *Msg {
 	return msg
 }
 
-func (msg *Msg) Image(url string) *Msg {
+// Image 图片，可以通过 options 设置 file、type、cache、proxy、timeout
+func (msg *Msg) Image(url string, options ...MediaOption) *Msg {
+	data := map[string]string{
+		"url": url,
+	}
+	for _, option := range options {
+		option(data)
+	}
 	msg.MessageList = append(msg.MessageList, &onebot.Message{
 		Type: "image",
-		Data: map[string]string{
-			"url": url,
-		},
+		Data: data,
 	})
 	return msg
 }
@@ -55,3 +64,243 @@ func (msg *Msg) At(qq int64) *Msg {
 	})
 	return msg
 }
+
+// AtAll @全体成员
+func (msg *Msg) AtAll() *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("at", "qq", "all"))
+	return msg
+}
+
+// Reply 回复某条消息，需要放在消息最前面
+func (msg *Msg) Reply(messageId int32) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("reply", "id", strconv.FormatInt(int64(messageId), 10)))
+	return msg
+}
+
+// Record 语音
+func (msg *Msg) Record(file string, options ...MediaOption) *Msg {
+	msg.MessageList = append(msg.MessageList, newMediaMessage("record", file, options))
+	return msg
+}
+
+// Video 短视频
+func (msg *Msg) Video(file string, options ...MediaOption) *Msg {
+	msg.MessageList = append(msg.MessageList, newMediaMessage("video", file, options))
+	return msg
+}
+
+// Rps 猜拳魔法表情
+func (msg *Msg) Rps() *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("rps"))
+	return msg
+}
+
+// Dice 掷骰子魔法表情
+func (msg *Msg) Dice() *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("dice"))
+	return msg
+}
+
+// Shake 窗口抖动（戳一戳）
+func (msg *Msg) Shake() *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("shake"))
+	return msg
+}
+
+// Poke 戳一戳
+func (msg *Msg) Poke(pokeType int, id int) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("poke", "type", strconv.Itoa(pokeType), "id", strconv.Itoa(id)))
+	return msg
+}
+
+// Anonymous 匿名发消息，ignore 为 true 时无法匿名也继续发送
+func (msg *Msg) Anonymous(ignore bool) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("anonymous", "ignore", boolData(ignore)))
+	return msg
+}
+
+// Share 链接分享
+func (msg *Msg) Share(url string, title string, content string, image string) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("share", "url", url, "title", title, "content", content, "image", image))
+	return msg
+}
+
+// ContactUser 推荐好友
+func (msg *Msg) ContactUser(userId int64) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("contact", "type", "qq", "id", strconv.FormatInt(userId, 10)))
+	return msg
+}
+
+// ContactGroup 推荐群
+func (msg *Msg) ContactGroup(groupId int64) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("contact", "type", "group", "id", strconv.FormatInt(groupId, 10)))
+	return msg
+}
+
+// Location 位置
+func (msg *Msg) Location(lat float64, lon float64, title string, content string) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("location",
+		"lat", strconv.FormatFloat(lat, 'f', -1, 64),
+		"lon", strconv.FormatFloat(lon, 'f', -1, 64),
+		"title", title,
+		"content", content,
+	))
+	return msg
+}
+
+// Music 音乐分享，platform 为 qq、163、xm
+func (msg *Msg) Music(platform string, id string) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("music", "type", platform, "id", id))
+	return msg
+}
+
+// CustomMusic 自定义音乐分享
+func (msg *Msg) CustomMusic(url string, audio string, title string, content string, image string) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("music", "type", "custom", "url", url, "audio", audio, "title", title, "content", content, "image", image))
+	return msg
+}
+
+// Xml XML消息
+func (msg *Msg) Xml(data string) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("xml", "data", data))
+	return msg
+}
+
+// Json JSON消息
+func (msg *Msg) Json(data string) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("json", "data", data))
+	return msg
+}
+
+// Forward 合并转发，id 为合并转发ID
+func (msg *Msg) Forward(id string) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("forward", "id", id))
+	return msg
+}
+
+// Node 合并转发节点，引用已有的消息
+func (msg *Msg) Node(messageId int32) *Msg {
+	msg.MessageList = append(msg.MessageList, newMessage("node", "id", strconv.FormatInt(int64(messageId), 10)))
+	return msg
+}
+
+// CustomNode 合并转发自定义节点，content 以 JSON 消息段数组的形式保存
+func (msg *Msg) CustomNode(name string, uin int64, content *Msg) *Msg {
+	msg.MessageList = append(msg.MessageList, newNodeMessage(name, uin, content.MessageList))
+	return msg
+}
+
+// Err 构造消息过程中的第一个错误，例如 ImageBytes 超过大小限制，发送时会直接返回该错误
+func (msg *Msg) Err() error {
+	return msg.err
+}
+
+func (msg *Msg) setErr(err error) *Msg {
+	if msg.err == nil {
+		msg.err = err
+	}
+	return msg
+}
+
+// ParseMsg 从收到的消息段列表构造 Msg，例如 event.Message
+func ParseMsg(messageList []*onebot.Message) *Msg {
+	msg := NewMsg()
+	for _, message := range messageList {
+		if message == nil {
+			continue
+		}
+		msg.MessageList = append(msg.MessageList, message)
+	}
+	return msg
+}
+
+// FromCQ 解析CQ码并追加到消息末尾，autoEscape 为 true 时与 SendGroupMessage 的 autoEscape 相同，不解析CQ码，整体作为纯文本
+func (msg *Msg) FromCQ(str string, autoEscape bool) *Msg {
+	if autoEscape {
+		msg.MessageList = append(msg.MessageList, cqcode.Text(str)...)
+	} else {
+		msg.MessageList = append(msg.MessageList, cqcode.Unmarshal(str)...)
+	}
+	return msg
+}
+
+// ToCQ 转换为CQ码字符串
+func (msg *Msg) ToCQ() string {
+	return cqcode.Marshal(msg.MessageList)
+}
+
+// Append 追加消息段，UnknownSegment 会原样写回
+func (msg *Msg) Append(segments ...Segment) *Msg {
+	for _, segment := range segments {
+		msg.MessageList = append(msg.MessageList, segment.Message())
+	}
+	return msg
+}
+
+// Segments 解析所有消息段
+func (msg *Msg) Segments() []Segment {
+	return ParseSegments(msg.MessageList)
+}
+
+// PlainText 拼接所有纯文本消息段
+func (msg *Msg) PlainText() string {
+	var sb strings.Builder
+	for _, segment := range msg.Segments() {
+		if text, ok := segment.(*TextSegment); ok {
+			sb.WriteString(text.Text)
+		}
+	}
+	return sb.String()
+}
+
+// Mentions 所有被@的QQ，不包括@全体成员
+func (msg *Msg) Mentions() []int64 {
+	mentions := make([]int64, 0)
+	for _, segment := range msg.Segments() {
+		if at, ok := segment.(*AtSegment); ok && !at.All {
+			mentions = append(mentions, at.QQ)
+		}
+	}
+	return mentions
+}
+
+// MentionsAll 是否@全体成员
+func (msg *Msg) MentionsAll() bool {
+	for _, segment := range msg.Segments() {
+		if at, ok := segment.(*AtSegment); ok && at.All {
+			return true
+		}
+	}
+	return false
+}
+
+// MentionsMe 是否@了 selfId，@全体成员不算
+func (msg *Msg) MentionsMe(selfId int64) bool {
+	for _, qq := range msg.Mentions() {
+		if qq == selfId {
+			return true
+		}
+	}
+	return false
+}
+
+// Images 所有图片消息段
+func (msg *Msg) Images() []*ImageSegment {
+	images := make([]*ImageSegment, 0)
+	for _, segment := range msg.Segments() {
+		if image, ok := segment.(*ImageSegment); ok {
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// GetReply 回复的消息段，没有时返回 nil
+func (msg *Msg) GetReply() *ReplySegment {
+	for _, segment := range msg.Segments() {
+		if reply, ok := segment.(*ReplySegment); ok {
+			return reply
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package pbbot
+
+import (
+	"strconv"
+
+	"github.com/ProtobufBot/go-pbbot/storage"
+)
+
+// Permission 权限等级，高等级包含低等级的权限
+type Permission int
+
+const (
+	PermissionEveryone   Permission = iota // 所有人
+	PermissionGroupAdmin                   // 群管理员
+	PermissionGroupOwner                   // 群主
+	PermissionSuperUser                    // 超级用户
+)
+
+func (p Permission) String() string {
+	switch p {
+	case PermissionEveryone:
+		return "everyone"
+	case PermissionGroupAdmin:
+		return "group_admin"
+	case PermissionGroupOwner:
+		return "group_owner"
+	case PermissionSuperUser:
+		return "super_user"
+	default:
+		return "permission(" + strconv.Itoa(int(p)) + ")"
+	}
+}
+
+// SuperUsers 超级用户，在所有群和私聊中拥有最高权限
+var SuperUsers = make(map[int64]bool)
+
+// HandlePermissionDenied 权限不足时调用，默认回复提示
+var HandlePermissionDenied = func(ctx *MessageContext, required Permission) {
+	_, _ = ctx.ReplyQuote(NewMsg().Text("权限不足"))
+}
+
+const permissionGrantKey = "permission_grant"
+
+// GrantPermission 在群中授予用户权限，保存在 DefaultStore 中
+func GrantPermission(botId int64, groupId int64, userId int64, permission Permission) error {
+	return grantBucket(botId, groupId).Set(strconv.FormatInt(userId, 10), []byte(strconv.Itoa(int(permission))), 0)
+}
+
+// RevokePermission 撤销 GrantPermission 授予的权限
+func RevokePermission(botId int64, groupId int64, userId int64) error {
+	return grantBucket(botId, groupId).Delete(strconv.FormatInt(userId, 10))
+}
+
+// GrantedPermission GrantPermission 授予的权限，没有时返回 PermissionEveryone
+func GrantedPermission(botId int64, groupId int64, userId int64) Permission {
+	data, err := grantBucket(botId, groupId).Get(strconv.FormatInt(userId, 10))
+	if err != nil {
+		return PermissionEveryone
+	}
+	permission, err := strconv.Atoi(string(data))
+	if err != nil {
+		return PermissionEveryone
+	}
+	return Permission(permission)
+}
+
+func grantBucket(botId int64, groupId int64) *storage.Bucket {
+	return storage.NewBucket(DefaultStore, storage.GroupNamespace(botId, groupId)).Sub(permissionGrantKey)
+}
+
+// Permission 发送者的权限，取超级用户、群角色和授予权限中最高的一个
+func (ctx *MessageContext) Permission() Permission {
+	if SuperUsers[ctx.UserId] {
+		return PermissionSuperUser
+	}
+	if !ctx.IsGroup() {
+		return PermissionEveryone
+	}
+	permission := PermissionEveryone
+	role := ""
+	if sender := ctx.GroupMessageEvent.GetSender(); sender != nil {
+		role = sender.Role
+	}
+	if role == "" && ctx.Bot.Cache != nil {
+		if member, ok := ctx.Bot.Cache.Member(ctx.GroupId, ctx.UserId); ok {
+			role = member.Role
+		}
+	}
+	switch role {
+	case RoleOwner:
+		permission = PermissionGroupOwner
+	case RoleAdmin:
+		permission = PermissionGroupAdmin
+	}
+	if granted := GrantedPermission(ctx.Bot.BotId, ctx.GroupId, ctx.UserId); granted > permission {
+		permission = granted
+	}
+	return permission
+}
+
+// HasPermission 发送者是否拥有 required 权限
+func (ctx *MessageContext) HasPermission(required Permission) bool {
+	return ctx.Permission() >= required
+}
+
+// checkPermission 检查权限并记录审计日志，权限不足时调用 HandlePermissionDenied
+func (ctx *MessageContext) checkPermission(required Permission, action string) bool {
+	if required <= PermissionEveryone {
+		return true
+	}
+	actual := ctx.Permission()
+	if actual < required {
+		ctx.Bot.Logger.Log(LevelWarn, "permission denied", F(FieldGroupId, ctx.GroupId), F(FieldUserId, ctx.UserId),
+			F("action", action), F("required", required.String()), F("actual", actual.String()))
+		HandlePermissionDenied(ctx, required)
+		return false
+	}
+	ctx.Bot.Logger.Log(LevelDebug, "permission granted", F(FieldGroupId, ctx.GroupId), F(FieldUserId, ctx.UserId),
+		F("action", action), F("required", required.String()), F("actual", actual.String()))
+	return true
+}
+
+// RequirePermission 包装消息处理函数，权限不足时不调用 handler
+func RequirePermission(required Permission, handler func(ctx *MessageContext)) func(ctx *MessageContext) {
+	return func(ctx *MessageContext) {
+		if ctx.checkPermission(required, "handler") {
+			handler(ctx)
+		}
+	}
+}
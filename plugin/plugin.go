@@ -0,0 +1,241 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/scheduler"
+	"github.com/ProtobufBot/go-pbbot/storage"
+)
+
+// Plugin 插件，在 Init 中通过 Registrar 注册消息处理函数、命令、定时任务
+type Plugin interface {
+	Name() string
+	Init(ctx context.Context, r *Registrar) error
+	Shutdown(ctx context.Context) error
+}
+
+type entry struct {
+	plugin    Plugin
+	handlers  []func(ctx *pbbot.MessageContext)
+	commands  []string
+	jobs      []string
+	panics    uint64
+	lastPanic time.Time
+}
+
+// Info 插件状态
+type Info struct {
+	Name      string
+	Commands  []string
+	Jobs      []string
+	Panics    uint64
+	LastPanic time.Time
+}
+
+// Manager 插件管理，插件的处理函数在 pbbot 消息中间件之后执行，panic 会被恢复并记录到对应插件
+type Manager struct {
+	Store     storage.Store
+	Scheduler *scheduler.Scheduler
+
+	// saveMu 在 mu 之前获取，保证开关状态按修改顺序保存
+	saveMu   sync.Mutex
+	mu       sync.RWMutex
+	plugins  map[string]*entry
+	order    []string
+	features []string
+	disabled map[string]map[int64]bool
+}
+
+// NewManager store 为插件存储使用的 Store，群开关状态也保存在其中，scheduler 为 nil 时插件不能注册定时任务
+func NewManager(store storage.Store, scheduler *scheduler.Scheduler) *Manager {
+	m := &Manager{
+		Store:     store,
+		Scheduler: scheduler,
+		plugins:   make(map[string]*entry),
+		disabled:  make(map[string]map[int64]bool),
+	}
+	m.loadToggles()
+	return m
+}
+
+// Install 把插件的消息处理函数接入 pbbot 的消息处理，被中间件丢弃的消息不会交给插件，只需要调用一次
+func (m *Manager) Install() {
+	pbbot.UseMessageHandler(m.dispatch)
+}
+
+// Register 注册并初始化插件，初始化失败时撤销已注册的内容
+func (m *Manager) Register(ctx context.Context, p Plugin) (err error) {
+	name := p.Name()
+	m.mu.Lock()
+	if _, ok := m.plugins[name]; ok {
+		m.mu.Unlock()
+		return fmt.Errorf("plugin %s already registered", name)
+	}
+	e := &entry{plugin: p}
+	m.plugins[name] = e
+	m.order = append(m.order, name)
+	m.mu.Unlock()
+
+	m.call(name, func() {
+		err = p.Init(ctx, &Registrar{manager: m, name: name, entry: e})
+	})
+	m.mu.RLock()
+	panicked := e.panics > 0
+	m.mu.RUnlock()
+	if err == nil && panicked {
+		err = fmt.Errorf("plugin %s panicked during init", name)
+	}
+	if err != nil {
+		m.remove(name)
+	}
+	return err
+}
+
+// Unregister 关闭插件并删除其注册的命令和定时任务
+func (m *Manager) Unregister(ctx context.Context, name string) error {
+	m.mu.RLock()
+	e, ok := m.plugins[name]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("plugin %s not found", name)
+	}
+	var err error
+	m.call(name, func() {
+		err = e.plugin.Shutdown(ctx)
+	})
+	m.remove(name)
+	return err
+}
+
+// Shutdown 按注册的相反顺序关闭所有插件
+func (m *Manager) Shutdown(ctx context.Context) {
+	m.mu.RLock()
+	names := append([]string(nil), m.order...)
+	m.mu.RUnlock()
+	for i := len(names) - 1; i >= 0; i-- {
+		if err := m.Unregister(ctx, names[i]); err != nil {
+			pbbot.DefaultLogger.Log(pbbot.LevelError, "failed to shutdown plugin", pbbot.F("plugin", names[i]), pbbot.Err(err))
+		}
+	}
+}
+
+// Enable 在群中启用插件或功能
+func (m *Manager) Enable(name string, groupId int64) error {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	m.mu.Lock()
+	delete(m.disabled[name], groupId)
+	groups := m.disabledGroups(name)
+	m.mu.Unlock()
+	return m.saveToggle(name, groups)
+}
+
+// Disable 在群中禁用插件或功能
+func (m *Manager) Disable(name string, groupId int64) error {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	m.mu.Lock()
+	if m.disabled[name] == nil {
+		m.disabled[name] = make(map[int64]bool)
+	}
+	m.disabled[name][groupId] = true
+	groups := m.disabledGroups(name)
+	m.mu.Unlock()
+	return m.saveToggle(name, groups)
+}
+
+// Enabled 插件在群中是否启用，groupId 为 0 表示私聊，私聊中总是启用
+func (m *Manager) Enabled(name string, groupId int64) bool {
+	if groupId == 0 {
+		return true
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return !m.disabled[name][groupId]
+}
+
+// Plugins 按注册顺序返回插件状态
+func (m *Manager) Plugins() []Info {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	infos := make([]Info, 0, len(m.order))
+	for _, name := range m.order {
+		e := m.plugins[name]
+		infos = append(infos, Info{
+			Name:      name,
+			Commands:  append([]string(nil), e.commands...),
+			Jobs:      append([]string(nil), e.jobs...),
+			Panics:    e.panics,
+			LastPanic: e.lastPanic,
+		})
+	}
+	return infos
+}
+
+func (m *Manager) dispatch(ctx *pbbot.MessageContext) {
+	m.mu.RLock()
+	type handler struct {
+		name string
+		fn   func(ctx *pbbot.MessageContext)
+	}
+	handlers := make([]handler, 0)
+	for _, name := range m.order {
+		if m.disabled[name][ctx.GroupId] && ctx.IsGroup() {
+			continue
+		}
+		for _, fn := range m.plugins[name].handlers {
+			handlers = append(handlers, handler{name, fn})
+		}
+	}
+	m.mu.RUnlock()
+	for _, h := range handlers {
+		m.call(h.name, func() {
+			h.fn(ctx)
+		})
+	}
+}
+
+// call 执行插件代码，panic 时记录插件名和堆栈
+func (m *Manager) call(name string, fn func()) {
+	defer func() {
+		if e := recover(); e != nil {
+			pbbot.DefaultLogger.Log(pbbot.LevelError, "plugin panicked", pbbot.F("plugin", name), pbbot.F("panic", e), pbbot.F("stack", string(debug.Stack())))
+			m.mu.Lock()
+			if entry, ok := m.plugins[name]; ok {
+				entry.panics++
+				entry.lastPanic = time.Now()
+			}
+			m.mu.Unlock()
+		}
+	}()
+	fn()
+}
+
+func (m *Manager) remove(name string) {
+	m.mu.Lock()
+	e, ok := m.plugins[name]
+	delete(m.plugins, name)
+	for i, n := range m.order {
+		if n == name {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	for _, command := range e.commands {
+		pbbot.UnregisterCommand(command)
+	}
+	if m.Scheduler != nil {
+		for _, job := range e.jobs {
+			m.Scheduler.Cancel(job)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"errors"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/scheduler"
+	"github.com/ProtobufBot/go-pbbot/storage"
+)
+
+var ErrNoScheduler = errors.New("plugin manager has no scheduler")
+
+// Registrar 插件注册内容的入口，注册的内容在插件注销时自动删除
+type Registrar struct {
+	manager *Manager
+	name    string
+	entry   *entry
+}
+
+func (r *Registrar) Name() string {
+	return r.name
+}
+
+// HandleMessage 注册消息处理函数，在插件被禁用的群中不会调用
+func (r *Registrar) HandleMessage(handler func(ctx *pbbot.MessageContext)) {
+	r.manager.mu.Lock()
+	defer r.manager.mu.Unlock()
+	r.entry.handlers = append(r.entry.handlers, handler)
+}
+
+// Command 注册命令，在插件被禁用的群中不会匹配
+func (r *Registrar) Command(command *pbbot.Command) {
+	handler := command.Handler
+	enabled := command.Enabled
+	registered := *command
+	registered.Handler = func(ctx *pbbot.MessageContext, args []string) {
+		r.manager.call(r.name, func() {
+			handler(ctx, args)
+		})
+	}
+	registered.Enabled = func(ctx *pbbot.MessageContext) bool {
+		if ctx.IsGroup() && !r.manager.Enabled(r.name, ctx.GroupId) {
+			return false
+		}
+		return enabled == nil || enabled(ctx)
+	}
+	pbbot.RegisterCommand(&registered)
+	r.manager.mu.Lock()
+	r.entry.commands = append(r.entry.commands, command.Name)
+	r.manager.mu.Unlock()
+}
+
+// Cron 注册定时任务，任务ID会加上插件名前缀
+func (r *Registrar) Cron(id string, expr string, botId int64, policy scheduler.OfflinePolicy, fn func(bot *pbbot.Bot)) error {
+	if r.manager.Scheduler == nil {
+		return ErrNoScheduler
+	}
+	jobId := "plugin:" + r.name + ":" + id
+	if err := r.manager.Scheduler.Cron(jobId, expr, botId, policy, func(bot *pbbot.Bot) {
+		r.manager.call(r.name, func() {
+			fn(bot)
+		})
+	}); err != nil {
+		return err
+	}
+	r.manager.mu.Lock()
+	r.entry.jobs = append(r.entry.jobs, jobId)
+	r.manager.mu.Unlock()
+	return nil
+}
+
+// Storage 插件的全局存储
+func (r *Registrar) Storage() *storage.Bucket {
+	return storage.NewBucket(r.manager.Store, "plugin:"+r.name)
+}
+
+// BotStorage 插件在机器人下的存储
+func (r *Registrar) BotStorage(botId int64) *storage.Bucket {
+	return storage.NewBucket(r.manager.Store, storage.BotNamespace(botId)).Sub("plugin:" + r.name)
+}
+
+// GroupStorage 插件在群中的存储
+func (r *Registrar) GroupStorage(botId int64, groupId int64) *storage.Bucket {
+	return storage.NewBucket(r.manager.Store, storage.GroupNamespace(botId, groupId)).Sub("plugin:" + r.name)
+}
+
+// UserStorage 插件在用户下的存储
+func (r *Registrar) UserStorage(botId int64, userId int64) *storage.Bucket {
+	return storage.NewBucket(r.manager.Store, storage.UserNamespace(botId, userId)).Sub("plugin:" + r.name)
+}
@@ -0,0 +1,210 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ProtobufBot/go-pbbot"
+)
+
+const toggleNamespace = "plugin/disabled"
+
+// AddFeature 添加不属于插件的功能开关，通过 Enabled 判断是否启用
+func (m *Manager) AddFeature(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, feature := range m.features {
+		if feature == name {
+			return
+		}
+	}
+	m.features = append(m.features, name)
+}
+
+// Features 所有可以开关的功能，包括插件和 AddFeature 添加的功能
+func (m *Manager) Features() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	features := append([]string(nil), m.order...)
+	for _, feature := range m.features {
+		if _, ok := m.plugins[feature]; !ok {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
+// DisabledGroups 功能被禁用的群
+func (m *Manager) DisabledGroups(name string) []int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.disabledGroups(name)
+}
+
+func (m *Manager) hasFeature(name string) bool {
+	for _, feature := range m.Features() {
+		if feature == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) disabledGroups(name string) []int64 {
+	groups := make([]int64, 0, len(m.disabled[name]))
+	for groupId := range m.disabled[name] {
+		groups = append(groups, groupId)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i] < groups[j] })
+	return groups
+}
+
+func (m *Manager) saveToggle(name string, groups []int64) error {
+	if m.Store == nil {
+		return nil
+	}
+	if len(groups) == 0 {
+		return m.Store.Delete(toggleNamespace, name)
+	}
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return err
+	}
+	return m.Store.Set(toggleNamespace, name, data, 0)
+}
+
+func (m *Manager) loadToggles() {
+	if m.Store == nil {
+		return
+	}
+	names, err := m.Store.Keys(toggleNamespace)
+	if err != nil {
+		pbbot.DefaultLogger.Log(pbbot.LevelError, "failed to load plugin toggles", pbbot.Err(err))
+		return
+	}
+	for _, name := range names {
+		data, err := m.Store.Get(toggleNamespace, name)
+		if err != nil {
+			continue
+		}
+		var groups []int64
+		if err := json.Unmarshal(data, &groups); err != nil {
+			pbbot.DefaultLogger.Log(pbbot.LevelError, "failed to parse plugin toggle", pbbot.F("plugin", name), pbbot.Err(err))
+			continue
+		}
+		m.disabled[name] = make(map[int64]bool, len(groups))
+		for _, groupId := range groups {
+			m.disabled[name][groupId] = true
+		}
+	}
+}
+
+// InstallCommands 注册管理命令，群管理员可以在群中开关功能，超级用户可以在私聊中指定群号
+//
+//	/enable <功能> [群号]
+//	/disable <功能> [群号]
+//	/features [群号]
+func (m *Manager) InstallCommands() {
+	pbbot.RegisterCommand(&pbbot.Command{
+		Name:       "enable",
+		Permission: pbbot.PermissionGroupAdmin,
+		Handler: func(ctx *pbbot.MessageContext, args []string) {
+			m.toggleCommand(ctx, args, true)
+		},
+	})
+	pbbot.RegisterCommand(&pbbot.Command{
+		Name:       "disable",
+		Permission: pbbot.PermissionGroupAdmin,
+		Handler: func(ctx *pbbot.MessageContext, args []string) {
+			m.toggleCommand(ctx, args, false)
+		},
+	})
+	pbbot.RegisterCommand(&pbbot.Command{
+		Name:       "features",
+		Permission: pbbot.PermissionGroupAdmin,
+		Handler:    m.featuresCommand,
+	})
+}
+
+// commandGroup 命令作用的群，只有超级用户可以指定其他群
+func commandGroup(ctx *pbbot.MessageContext, args []string) (int64, bool) {
+	if len(args) > 0 && ctx.HasPermission(pbbot.PermissionSuperUser) {
+		groupId, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return groupId, true
+	}
+	return ctx.GroupId, ctx.IsGroup()
+}
+
+func (m *Manager) toggleCommand(ctx *pbbot.MessageContext, args []string, enable bool) {
+	if len(args) == 0 {
+		_, _ = ctx.ReplyText("用法: <功能> [群号]")
+		return
+	}
+	name := args[0]
+	if !m.hasFeature(name) {
+		_, _ = ctx.ReplyText(fmt.Sprintf("功能 %s 不存在", name))
+		return
+	}
+	groupId, ok := commandGroup(ctx, args[1:])
+	if !ok {
+		_, _ = ctx.ReplyText("请在群中使用或指定群号")
+		return
+	}
+	var err error
+	if enable {
+		err = m.Enable(name, groupId)
+	} else {
+		err = m.Disable(name, groupId)
+	}
+	if err != nil {
+		ctx.Bot.Logger.Log(pbbot.LevelError, "failed to save plugin toggle", pbbot.F("plugin", name), pbbot.Err(err))
+		_, _ = ctx.ReplyText("保存失败")
+		return
+	}
+	ctx.Bot.Logger.Log(pbbot.LevelInfo, "plugin toggled", pbbot.F("plugin", name), pbbot.F("enabled", enable), pbbot.F(pbbot.FieldGroupId, groupId), pbbot.F(pbbot.FieldUserId, ctx.UserId))
+	state := "禁用"
+	if enable {
+		state = "启用"
+	}
+	_, _ = ctx.ReplyText(fmt.Sprintf("已在群 %d %s %s", groupId, state, name))
+}
+
+func (m *Manager) featuresCommand(ctx *pbbot.MessageContext, args []string) {
+	features := m.Features()
+	if len(features) == 0 {
+		_, _ = ctx.ReplyText("没有可用的功能")
+		return
+	}
+	lines := make([]string, 0, len(features))
+	groupId, ok := commandGroup(ctx, args)
+	if ok {
+		for _, name := range features {
+			state := "启用"
+			if !m.Enabled(name, groupId) {
+				state = "禁用"
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", name, state))
+		}
+	} else {
+		// 私聊中未指定群号时列出每个功能被禁用的群
+		for _, name := range features {
+			groups := m.DisabledGroups(name)
+			if len(groups) == 0 {
+				lines = append(lines, fmt.Sprintf("%s: 全部启用", name))
+				continue
+			}
+			ids := make([]string, 0, len(groups))
+			for _, id := range groups {
+				ids = append(ids, strconv.FormatInt(id, 10))
+			}
+			lines = append(lines, fmt.Sprintf("%s: 禁用于 %s", name, strings.Join(ids, ", ")))
+		}
+	}
+	_, _ = ctx.ReplyText(strings.Join(lines, "\n"))
+}
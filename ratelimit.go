@@ -0,0 +1,335 @@
+package pbbot
+
+import (
+	"container/heap"
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// Priority 发送优先级，机器人级别的限流按优先级排队，同优先级先到先发
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1 // 踢人、禁言、撤回等管理操作的默认优先级
+)
+
+// RateLimit 令牌桶参数，Rate 为每秒生成的令牌数，Rate 为 0 表示不限制
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig 分别对机器人、每个群、每个私聊用户限流
+type RateLimitConfig struct {
+	Bot   RateLimit
+	Group RateLimit
+	User  RateLimit
+}
+
+// DefaultRateLimitConfig 不为 nil 时，新连接的机器人使用该配置创建 RateLimiter
+var DefaultRateLimitConfig *RateLimitConfig
+
+// RateLimiterStats 限流统计
+type RateLimiterStats struct {
+	Allowed   uint64        // 通过的请求数
+	Throttled uint64        // 需要等待的请求数
+	Cancelled uint64        // 等待过程中 ctx 被取消的请求数
+	Waiting   int64         // 正在等待的请求数
+	WaitTime  time.Duration // 累计等待时间
+}
+
+type priorityContextKey struct{}
+
+// WithPriority 设置 ctx 中API调用的发送优先级，用于 Bot.Send 等接受 ctx 的方法
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityContextKey{}, priority)
+}
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit, now time.Time) *tokenBucket {
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	return &tokenBucket{
+		limit:  RateLimit{Rate: limit.Rate, Burst: burst},
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) advance(now time.Time) {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+		if b.tokens > float64(b.limit.Burst) {
+			b.tokens = float64(b.limit.Burst)
+		}
+		b.last = now
+	}
+}
+
+// reserve 预定一个令牌，返回需要等待的时间，令牌可以为负数
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.advance(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
+
+// delay 距离有一个可用令牌的时间
+func (b *tokenBucket) delay(now time.Time) time.Duration {
+	b.advance(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+type rateWaiter struct {
+	priority Priority
+	seq      uint64
+	index    int
+}
+
+type rateWaiterQueue []*rateWaiter
+
+func (q rateWaiterQueue) Len() int { return len(q) }
+func (q rateWaiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+func (q rateWaiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *rateWaiterQueue) Push(x interface{}) {
+	w := x.(*rateWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+func (q *rateWaiterQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return w
+}
+
+// RateLimiter 发送限流，先按群或用户限流，再进入机器人级别的优先级队列
+type RateLimiter struct {
+	config RateLimitConfig
+
+	mu      sync.Mutex
+	bot     *tokenBucket
+	keyed   map[string]*tokenBucket
+	queue   rateWaiterQueue
+	seq     uint64
+	changed chan struct{}
+
+	allowed   uint64
+	throttled uint64
+	cancelled uint64
+	waiting   int64
+	waitTime  int64
+}
+
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	now := time.Now()
+	l := &RateLimiter{
+		config:  config,
+		keyed:   make(map[string]*tokenBucket),
+		changed: make(chan struct{}),
+	}
+	if config.Bot.Rate > 0 {
+		l.bot = newTokenBucket(config.Bot, now)
+	}
+	return l
+}
+
+// Wait 等待发送许可，groupId 或 userId 为 0 时不进行对应的限流
+func (l *RateLimiter) Wait(ctx context.Context, groupId int64, userId int64, priority Priority) error {
+	start := time.Now()
+	atomic.AddInt64(&l.waiting, 1)
+	defer atomic.AddInt64(&l.waiting, -1)
+
+	delay := time.Duration(0)
+	reserved := make([]*tokenBucket, 0, 2)
+	l.mu.Lock()
+	if groupId != 0 && l.config.Group.Rate > 0 {
+		bucket := l.keyedBucket("group:"+strconv.FormatInt(groupId, 10), l.config.Group, start)
+		delay = maxDuration(delay, bucket.reserve(start))
+		reserved = append(reserved, bucket)
+	}
+	if userId != 0 && l.config.User.Rate > 0 {
+		bucket := l.keyedBucket("user:"+strconv.FormatInt(userId, 10), l.config.User, start)
+		delay = maxDuration(delay, bucket.reserve(start))
+		reserved = append(reserved, bucket)
+	}
+	l.mu.Unlock()
+	cancel := func(err error) error {
+		l.mu.Lock()
+		for _, bucket := range reserved {
+			bucket.tokens++
+		}
+		l.mu.Unlock()
+		atomic.AddUint64(&l.cancelled, 1)
+		return err
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return cancel(ctx.Err())
+		}
+	}
+	if err := l.waitBot(ctx, priority); err != nil {
+		return cancel(err)
+	}
+
+	atomic.AddUint64(&l.allowed, 1)
+	if waited := time.Since(start); waited > time.Millisecond {
+		atomic.AddUint64(&l.throttled, 1)
+		atomic.AddInt64(&l.waitTime, int64(waited))
+	}
+	return nil
+}
+
+func (l *RateLimiter) waitBot(ctx context.Context, priority Priority) error {
+	if l.bot == nil {
+		return nil
+	}
+	l.mu.Lock()
+	l.seq++
+	w := &rateWaiter{priority: priority, seq: l.seq}
+	heap.Push(&l.queue, w)
+	for {
+		delay := time.Duration(-1)
+		if l.queue[0] == w {
+			now := time.Now()
+			delay = l.bot.delay(now)
+			if delay == 0 {
+				l.bot.reserve(now)
+				heap.Remove(&l.queue, w.index)
+				l.notifyLocked()
+				l.mu.Unlock()
+				return nil
+			}
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if delay > 0 {
+			timer = time.NewTimer(delay)
+			timeout = timer.C
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+		case <-ctx.Done():
+			l.mu.Lock()
+			heap.Remove(&l.queue, w.index)
+			l.notifyLocked()
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		l.mu.Lock()
+	}
+}
+
+// notifyLocked 队列变化时唤醒所有等待者
+func (l *RateLimiter) notifyLocked() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *RateLimiter) keyedBucket(key string, limit RateLimit, now time.Time) *tokenBucket {
+	bucket, ok := l.keyed[key]
+	if !ok {
+		if len(l.keyed) >= 10000 {
+			l.pruneLocked(now)
+		}
+		bucket = newTokenBucket(limit, now)
+		l.keyed[key] = bucket
+	}
+	return bucket
+}
+
+// pruneLocked 删除已经回满的令牌桶，效果与新建的相同
+func (l *RateLimiter) pruneLocked(now time.Time) {
+	for key, bucket := range l.keyed {
+		if bucket.delay(now) == 0 && bucket.tokens >= float64(bucket.limit.Burst) {
+			delete(l.keyed, key)
+		}
+	}
+}
+
+func (l *RateLimiter) Stats() RateLimiterStats {
+	return RateLimiterStats{
+		Allowed:   atomic.LoadUint64(&l.allowed),
+		Throttled: atomic.LoadUint64(&l.throttled),
+		Cancelled: atomic.LoadUint64(&l.cancelled),
+		Waiting:   atomic.LoadInt64(&l.waiting),
+		WaitTime:  time.Duration(atomic.LoadInt64(&l.waitTime)),
+	}
+}
+
+func maxDuration(a time.Duration, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// rateLimitKey 需要限流的请求返回群号、用户和默认优先级，查询类请求不限流
+func rateLimitKey(frame *onebot.Frame) (groupId int64, userId int64, priority Priority, limited bool) {
+	switch data := frame.Data.(type) {
+	case *onebot.Frame_SendPrivateMsgReq:
+		return 0, data.SendPrivateMsgReq.GetUserId(), PriorityNormal, true
+	case *onebot.Frame_SendGroupMsgReq:
+		return data.SendGroupMsgReq.GetGroupId(), 0, PriorityNormal, true
+	case *onebot.Frame_SendMsgReq:
+		if data.SendMsgReq.GetMessageType() == MessageTypeGroup {
+			return data.SendMsgReq.GetGroupId(), 0, PriorityNormal, true
+		}
+		return 0, data.SendMsgReq.GetUserId(), PriorityNormal, true
+	case *onebot.Frame_SendLikeReq:
+		return 0, data.SendLikeReq.GetUserId(), PriorityLow, true
+	case *onebot.Frame_DeleteMsgReq,
+		*onebot.Frame_SetGroupKickReq,
+		*onebot.Frame_SetGroupBanReq,
+		*onebot.Frame_SetGroupAnonymousBanReq,
+		*onebot.Frame_SetGroupWholeBanReq,
+		*onebot.Frame_SetGroupAdminReq,
+		*onebot.Frame_SetGroupCardReq,
+		*onebot.Frame_SetGroupNameReq,
+		*onebot.Frame_SetGroupLeaveReq,
+		*onebot.Frame_SetGroupSpecialTitleReq,
+		*onebot.Frame_SetFriendAddRequestReq,
+		*onebot.Frame_SetGroupAddRequestReq:
+		return 0, 0, PriorityHigh, true
+	}
+	return 0, 0, PriorityNormal, false
+}
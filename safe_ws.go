@@ -1,9 +1,12 @@
 package pbbot
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
+
 	"github.com/ProtobufBot/go-pbbot/util"
 	"github.com/gorilla/websocket"
-	log "github.com/sirupsen/logrus"
 )
 
 // safe websocket
@@ -12,6 +15,11 @@ type SafeWebSocket struct {
 	SendChannel   chan *WebSocketSendingMessage
 	OnRecvMessage func(messageType int, data []byte)
 	OnClose       func(int, string)
+	Logger        Logger
+
+	closeOnce       sync.Once
+	done            chan struct{}
+	lastMessageType int32
 }
 
 type WebSocketSendingMessage struct {
@@ -19,23 +27,39 @@ type WebSocketSendingMessage struct {
 	Data        []byte
 }
 
+// Send 把消息加入发送队列，连接断开后丢弃消息
 func (ws *SafeWebSocket) Send(messageType int, data []byte) {
-	ws.SendChannel <- &WebSocketSendingMessage{
-		MessageType: messageType,
-		Data:        data,
+	_ = ws.sendContext(context.Background(), messageType, data)
+}
+
+// sendContext 把消息加入发送队列，队列满时等待，连接断开时返回 ErrDisconnected
+func (ws *SafeWebSocket) sendContext(ctx context.Context, messageType int, data []byte) error {
+	select {
+	case ws.SendChannel <- &WebSocketSendingMessage{MessageType: messageType, Data: data}:
+		return nil
+	case <-ws.done:
+		return ErrDisconnected
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 func NewSafeWebSocket(conn *websocket.Conn, OnRecvMessage func(messageType int, data []byte), onClose func(int, string)) *SafeWebSocket {
+	return newSafeWebSocket(conn, OnRecvMessage, onClose, DefaultLogger)
+}
+
+func newSafeWebSocket(conn *websocket.Conn, OnRecvMessage func(messageType int, data []byte), onClose func(int, string), logger Logger) *SafeWebSocket {
 	ws := &SafeWebSocket{
 		Conn:          conn,
 		SendChannel:   make(chan *WebSocketSendingMessage, 100),
 		OnRecvMessage: OnRecvMessage,
 		OnClose:       onClose,
+		Logger:        logger,
+		done:          make(chan struct{}),
 	}
 
 	conn.SetCloseHandler(func(code int, text string) error {
-		ws.OnClose(code, text)
+		ws.close(code, text)
 		return nil
 	})
 
@@ -44,10 +68,12 @@ func NewSafeWebSocket(conn *websocket.Conn, OnRecvMessage func(messageType int,
 		for {
 			messageType, data, err := conn.ReadMessage()
 			if err != nil {
-				log.Errorf("failed to read message, err: %+v", err)
+				ws.Logger.Log(LevelError, "failed to read message", Err(err))
 				_ = conn.Close()
+				ws.close(websocket.CloseAbnormalClosure, err.Error())
 				return
 			}
+			atomic.StoreInt32(&ws.lastMessageType, int32(messageType))
 			if messageType == websocket.PingMessage {
 				ws.Send(websocket.PongMessage, []byte("pong"))
 				continue
@@ -60,12 +86,12 @@ func NewSafeWebSocket(conn *websocket.Conn, OnRecvMessage func(messageType int,
 	util.SafeGo(func() {
 		for sendingMessage := range ws.SendChannel {
 			if ws.Conn == nil {
-				log.Errorf("failed to send websocket message, conn is nil")
+				ws.Logger.Log(LevelError, "failed to send websocket message, conn is nil")
 				return
 			}
 			err := ws.Conn.WriteMessage(sendingMessage.MessageType, sendingMessage.Data)
 			if err != nil {
-				log.Errorf("failed to send websocket message, %+v", err)
+				ws.Logger.Log(LevelError, "failed to send websocket message", Err(err))
 				_ = conn.Close()
 				return
 			}
@@ -73,3 +99,33 @@ func NewSafeWebSocket(conn *websocket.Conn, OnRecvMessage func(messageType int,
 	})
 	return ws
 }
+
+// Encoding 最近收到的消息编码，protobuf 或 json，还没有收到消息时为空
+func (ws *SafeWebSocket) Encoding() string {
+	switch atomic.LoadInt32(&ws.lastMessageType) {
+	case websocket.BinaryMessage:
+		return "protobuf"
+	case websocket.TextMessage:
+		return "json"
+	default:
+		return ""
+	}
+}
+
+// Done 连接断开时关闭
+func (ws *SafeWebSocket) Done() <-chan struct{} {
+	return ws.done
+}
+
+// QueueDepth 等待发送的消息数量
+func (ws *SafeWebSocket) QueueDepth() int {
+	return len(ws.SendChannel)
+}
+
+// close 连接断开时调用 OnClose，收到关闭帧和读取出错时只调用一次
+func (ws *SafeWebSocket) close(code int, text string) {
+	ws.closeOnce.Do(func() {
+		close(ws.done)
+		ws.OnClose(code, text)
+	})
+}
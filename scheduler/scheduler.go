@@ -0,0 +1,352 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/ProtobufBot/go-pbbot/storage"
+	"github.com/ProtobufBot/go-pbbot/util"
+)
+
+// OfflinePolicy 任务触发时机器人不在线的处理方式
+type OfflinePolicy int
+
+const (
+	OfflineSkip  OfflinePolicy = iota // 跳过本次执行
+	OfflineDefer                      // 等待机器人上线后执行，超过 MaxDefer 后跳过
+)
+
+const reminderNamespace = "scheduler/reminders"
+
+// Job 定时任务，Cron 任务每次触发都会执行，一次性任务执行或跳过后删除
+type Job struct {
+	Id      string
+	BotId   int64
+	Policy  OfflinePolicy
+	Func    func(bot *pbbot.Bot)
+	cron    *CronSchedule
+	next    time.Time
+	persist bool
+}
+
+func (job *Job) Next() time.Time {
+	return job.next
+}
+
+// reminder 持久化的一次性消息提醒
+type reminder struct {
+	BotId   int64             `json:"bot_id"`
+	Target  pbbot.Target      `json:"target"`
+	Message []*onebot.Message `json:"message"`
+	At      time.Time         `json:"at"`
+	Policy  OfflinePolicy     `json:"policy"`
+}
+
+type deferredRun struct {
+	job      *Job
+	deadline time.Time
+}
+
+// Scheduler 定时任务调度器，每秒检查一次到期的任务
+type Scheduler struct {
+	// MaxDefer OfflineDefer 任务最多等待机器人上线的时间
+	MaxDefer time.Duration
+	// Store 一次性提醒的持久化存储，为 nil 时不持久化
+	Store storage.Store
+
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	deferred map[string]*deferredRun // 每个任务最多一个等待机器人上线的执行
+	stop     chan struct{}
+}
+
+func New(store storage.Store) *Scheduler {
+	return &Scheduler{
+		MaxDefer: time.Hour,
+		Store:    store,
+		jobs:     make(map[string]*Job),
+		deferred: make(map[string]*deferredRun),
+	}
+}
+
+// Start 加载持久化的提醒并开始调度
+func (s *Scheduler) Start() error {
+	if err := s.loadReminders(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return nil
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+	s.mu.Unlock()
+	util.SafeGo(func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.Tick(now)
+			case <-stop:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// Cron 添加 cron 任务，id 相同时替换原任务
+func (s *Scheduler) Cron(id string, expr string, botId int64, policy OfflinePolicy, fn func(bot *pbbot.Bot)) error {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		return err
+	}
+	s.add(&Job{
+		Id:     id,
+		BotId:  botId,
+		Policy: policy,
+		Func:   fn,
+		cron:   schedule,
+		next:   schedule.Next(time.Now()),
+	})
+	return nil
+}
+
+// CronSend 添加定时发送消息的 cron 任务
+func (s *Scheduler) CronSend(id string, expr string, botId int64, target pbbot.Target, msg *pbbot.Msg, policy OfflinePolicy) error {
+	return s.Cron(id, expr, botId, policy, sendFunc(target, msg.MessageList))
+}
+
+// After 添加延迟执行的一次性任务，不会持久化，返回任务ID
+func (s *Scheduler) After(delay time.Duration, botId int64, policy OfflinePolicy, fn func(bot *pbbot.Bot)) string {
+	id := "after:" + util.GenerateIdStr()
+	s.add(&Job{
+		Id:     id,
+		BotId:  botId,
+		Policy: policy,
+		Func:   fn,
+		next:   time.Now().Add(delay),
+	})
+	return id
+}
+
+// Remind 在 at 时刻发送消息，设置了 Store 时会持久化，重启后仍然有效，返回任务ID
+func (s *Scheduler) Remind(at time.Time, botId int64, target pbbot.Target, msg *pbbot.Msg, policy OfflinePolicy) (string, error) {
+	if err := msg.Err(); err != nil {
+		return "", err
+	}
+	id := fmt.Sprintf("remind:%d:%s", time.Now().UnixNano(), util.GenerateIdStr())
+	r := &reminder{
+		BotId:   botId,
+		Target:  target,
+		Message: msg.MessageList,
+		At:      at,
+		Policy:  policy,
+	}
+	if s.Store != nil {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return "", err
+		}
+		if err := s.Store.Set(reminderNamespace, id, data, 0); err != nil {
+			return "", err
+		}
+	}
+	s.addReminder(id, r)
+	return id, nil
+}
+
+// Cancel 取消任务，包括正在等待机器人上线的任务
+func (s *Scheduler) Cancel(id string) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if run, deferred := s.deferred[id]; !ok && deferred {
+		job, ok = run.job, true
+	}
+	delete(s.jobs, id)
+	delete(s.deferred, id)
+	s.mu.Unlock()
+	if ok && job.persist {
+		s.removeReminder(id)
+	}
+}
+
+// Jobs 所有等待执行的任务的副本
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+func (s *Scheduler) add(job *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.Id] = job
+}
+
+func (s *Scheduler) addReminder(id string, r *reminder) {
+	s.add(&Job{
+		Id:      id,
+		BotId:   r.BotId,
+		Policy:  r.Policy,
+		Func:    sendFunc(r.Target, r.Message),
+		next:    r.At,
+		persist: true,
+	})
+}
+
+func (s *Scheduler) loadReminders() error {
+	if s.Store == nil {
+		return nil
+	}
+	ids, err := s.Store.Keys(reminderNamespace)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		data, err := s.Store.Get(reminderNamespace, id)
+		if err != nil {
+			continue
+		}
+		var r reminder
+		if err := json.Unmarshal(data, &r); err != nil {
+			pbbot.DefaultLogger.Log(pbbot.LevelError, "failed to load reminder", pbbot.F("job_id", id), pbbot.Err(err))
+			continue
+		}
+		s.addReminder(id, &r)
+	}
+	return nil
+}
+
+func (s *Scheduler) removeReminder(id string) {
+	if s.Store == nil {
+		return
+	}
+	if err := s.Store.Delete(reminderNamespace, id); err != nil {
+		pbbot.DefaultLogger.Log(pbbot.LevelError, "failed to delete reminder", pbbot.F("job_id", id), pbbot.Err(err))
+	}
+}
+
+// Tick 执行到期的任务和等待机器人上线的任务，Start 后每秒调用一次，也可以不调用 Start 由外部驱动
+func (s *Scheduler) Tick(now time.Time) {
+	due := make([]*Job, 0)
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		if job.next.IsZero() || now.Before(job.next) {
+			continue
+		}
+		due = append(due, job)
+		if job.cron != nil {
+			job.next = job.cron.Next(now)
+		} else {
+			// 一次性任务保留到 finish，期间可以被 Cancel
+			job.next = time.Time{}
+		}
+	}
+	deferred := make([]*deferredRun, 0, len(s.deferred))
+	for _, run := range s.deferred {
+		deferred = append(deferred, run)
+	}
+	s.mu.Unlock()
+
+	for _, run := range deferred {
+		if s.run(run.job) {
+			s.undefer(run)
+			continue
+		}
+		if !now.Before(run.deadline) {
+			pbbot.DefaultLogger.Log(pbbot.LevelWarn, "scheduled job skipped, bot offline for too long", pbbot.F("job_id", run.job.Id), pbbot.F(pbbot.FieldBotId, run.job.BotId))
+			s.undefer(run)
+			s.finish(run.job)
+		}
+	}
+	for _, job := range due {
+		if s.run(job) {
+			continue
+		}
+		if job.Policy == OfflineDefer {
+			s.deferJob(&deferredRun{job: job, deadline: now.Add(s.MaxDefer)})
+			continue
+		}
+		pbbot.DefaultLogger.Log(pbbot.LevelWarn, "scheduled job skipped, bot offline", pbbot.F("job_id", job.Id), pbbot.F(pbbot.FieldBotId, job.BotId))
+		s.finish(job)
+	}
+}
+
+// deferJob 任务已经在等待时保留原来的截止时间，cron 任务在机器人离线期间多次触发也只执行一次，已取消的任务不再等待
+func (s *Scheduler) deferJob(run *deferredRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jobs[run.job.Id] != run.job {
+		return
+	}
+	if _, ok := s.deferred[run.job.Id]; ok {
+		return
+	}
+	s.deferred[run.job.Id] = run
+}
+
+// undefer 删除等待中的执行，run 已被 Cancel 或替换时不做处理
+func (s *Scheduler) undefer(run *deferredRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.deferred[run.job.Id] == run {
+		delete(s.deferred, run.job.Id)
+	}
+}
+
+// run 机器人在线时执行任务并返回 true
+func (s *Scheduler) run(job *Job) bool {
+	bot, ok := pbbot.GetBot(job.BotId)
+	if !ok {
+		return false
+	}
+	util.SafeGo(func() {
+		defer s.finish(job)
+		job.Func(bot)
+	})
+	return true
+}
+
+// finish 一次性任务执行或跳过后删除，已被 Cancel 的任务由 Cancel 删除
+func (s *Scheduler) finish(job *Job) {
+	if job.cron != nil {
+		return
+	}
+	s.mu.Lock()
+	removed := s.jobs[job.Id] == job
+	if removed {
+		delete(s.jobs, job.Id)
+	}
+	s.mu.Unlock()
+	if removed && job.persist {
+		s.removeReminder(job.Id)
+	}
+}
+
+func sendFunc(target pbbot.Target, messageList []*onebot.Message) func(bot *pbbot.Bot) {
+	return func(bot *pbbot.Bot) {
+		if _, err := bot.Send(bot.Context(), target, pbbot.ParseMsg(messageList)); err != nil {
+			bot.Logger.Log(pbbot.LevelError, "failed to send scheduled message", pbbot.F(pbbot.FieldGroupId, target.GroupId), pbbot.F(pbbot.FieldUserId, target.UserId), pbbot.Err(err))
+		}
+	}
+}
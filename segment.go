@@ -0,0 +1,316 @@
+package pbbot
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/ProtobufBot/go-pbbot/cqcode"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// Segment 消息段，可以通过 ParseSegment 从 onebot.Message 解析得到，也可以通过 Message 转换回 onebot.Message
+// 已知类型的消息段中未识别的数据保存在 Extra 中，转换回 onebot.Message 时原样保留
+type Segment interface {
+	Type() string
+	Message() *onebot.Message
+}
+
+// TextSegment 纯文本
+type TextSegment struct {
+	Text  string
+	Extra map[string]string
+}
+
+// FaceSegment QQ表情
+type FaceSegment struct {
+	Id    int
+	Extra map[string]string
+}
+
+// AtSegment @某人，All 为 true 时表示@全体成员
+type AtSegment struct {
+	QQ    int64
+	All   bool
+	Extra map[string]string
+}
+
+// ImageSegment 图片
+type ImageSegment struct {
+	File      string
+	Url       string
+	ImageType string
+	Extra     map[string]string
+}
+
+// RecordSegment 语音
+type RecordSegment struct {
+	File  string
+	Url   string
+	Extra map[string]string
+}
+
+// VideoSegment 短视频
+type VideoSegment struct {
+	File  string
+	Url   string
+	Extra map[string]string
+}
+
+// ReplySegment 回复
+type ReplySegment struct {
+	Id    int32
+	Extra map[string]string
+}
+
+// ForwardSegment 合并转发
+type ForwardSegment struct {
+	Id    string
+	Extra map[string]string
+}
+
+// NodeSegment 合并转发节点，Id 不为 0 时引用已有消息，否则为自定义节点，Content 中可能嵌套 NodeSegment
+type NodeSegment struct {
+	Id      int32
+	Name    string
+	Uin     int64
+	Content []Segment
+	Extra   map[string]string
+}
+
+// XmlSegment XML消息
+type XmlSegment struct {
+	Data  string
+	Extra map[string]string
+}
+
+// JsonSegment JSON消息
+type JsonSegment struct {
+	Data  string
+	Extra map[string]string
+}
+
+// UnknownSegment 未识别的消息段，原样保留类型和数据
+type UnknownSegment struct {
+	SegmentType string
+	Data        map[string]string
+}
+
+func (seg *TextSegment) Type() string    { return "text" }
+func (seg *FaceSegment) Type() string    { return "face" }
+func (seg *AtSegment) Type() string      { return "at" }
+func (seg *ImageSegment) Type() string   { return "image" }
+func (seg *RecordSegment) Type() string  { return "record" }
+func (seg *VideoSegment) Type() string   { return "video" }
+func (seg *ReplySegment) Type() string   { return "reply" }
+func (seg *ForwardSegment) Type() string { return "forward" }
+func (seg *NodeSegment) Type() string    { return "node" }
+func (seg *XmlSegment) Type() string     { return "xml" }
+func (seg *JsonSegment) Type() string    { return "json" }
+func (seg *UnknownSegment) Type() string { return seg.SegmentType }
+
+func (seg *TextSegment) Message() *onebot.Message {
+	message := &onebot.Message{
+		Type: seg.Type(),
+		Data: map[string]string{
+			"text": seg.Text,
+		},
+	}
+	return withExtra(message, seg.Extra)
+}
+
+func (seg *FaceSegment) Message() *onebot.Message {
+	return withExtra(newMessage(seg.Type(), "id", strconv.Itoa(seg.Id)), seg.Extra)
+}
+
+func (seg *AtSegment) Message() *onebot.Message {
+	if seg.All {
+		return withExtra(newMessage(seg.Type(), "qq", "all"), seg.Extra)
+	}
+	return withExtra(newMessage(seg.Type(), "qq", strconv.FormatInt(seg.QQ, 10)), seg.Extra)
+}
+
+func (seg *ImageSegment) Message() *onebot.Message {
+	return withExtra(newMessage(seg.Type(), "file", seg.File, "url", seg.Url, "type", seg.ImageType), seg.Extra)
+}
+
+func (seg *RecordSegment) Message() *onebot.Message {
+	return withExtra(newMessage(seg.Type(), "file", seg.File, "url", seg.Url), seg.Extra)
+}
+
+func (seg *VideoSegment) Message() *onebot.Message {
+	return withExtra(newMessage(seg.Type(), "file", seg.File, "url", seg.Url), seg.Extra)
+}
+
+func (seg *ReplySegment) Message() *onebot.Message {
+	return withExtra(newMessage(seg.Type(), "id", strconv.FormatInt(int64(seg.Id), 10)), seg.Extra)
+}
+
+func (seg *ForwardSegment) Message() *onebot.Message {
+	return withExtra(newMessage(seg.Type(), "id", seg.Id), seg.Extra)
+}
+
+func (seg *NodeSegment) Message() *onebot.Message {
+	if seg.Id != 0 {
+		return withExtra(newMessage(seg.Type(), "id", strconv.FormatInt(int64(seg.Id), 10)), seg.Extra)
+	}
+	content := make([]*onebot.Message, 0, len(seg.Content))
+	for _, segment := range seg.Content {
+		content = append(content, segment.Message())
+	}
+	return withExtra(newNodeMessage(seg.Name, seg.Uin, content), seg.Extra)
+}
+
+// newNodeMessage 构造自定义合并转发节点，content 编码为 JSON 消息段数组
+func newNodeMessage(name string, uin int64, content []*onebot.Message) *onebot.Message {
+	contentData, _ := json.Marshal(content)
+	return newMessage("node", "name", name, "uin", strconv.FormatInt(uin, 10), "content", string(contentData))
+}
+
+func (seg *XmlSegment) Message() *onebot.Message {
+	return withExtra(newMessage(seg.Type(), "data", seg.Data), seg.Extra)
+}
+
+func (seg *JsonSegment) Message() *onebot.Message {
+	return withExtra(newMessage(seg.Type(), "data", seg.Data), seg.Extra)
+}
+
+func (seg *UnknownSegment) Message() *onebot.Message {
+	return &onebot.Message{
+		Type: seg.SegmentType,
+		Data: copyData(seg.Data),
+	}
+}
+
+// ParseSegment 解析单个消息段，无法识别或数据不合法时返回 UnknownSegment
+func ParseSegment(message *onebot.Message) Segment {
+	data := message.Data
+	switch message.Type {
+	case "text":
+		return &TextSegment{Text: data["text"], Extra: extraData(data, "text")}
+	case "face":
+		if id, err := strconv.Atoi(data["id"]); err == nil {
+			return &FaceSegment{Id: id, Extra: extraData(data, "id")}
+		}
+	case "at":
+		if data["qq"] == "all" {
+			return &AtSegment{All: true, Extra: extraData(data, "qq")}
+		}
+		if qq, err := strconv.ParseInt(data["qq"], 10, 64); err == nil {
+			return &AtSegment{QQ: qq, Extra: extraData(data, "qq")}
+		}
+	case "image":
+		return &ImageSegment{File: data["file"], Url: data["url"], ImageType: data["type"], Extra: extraData(data, "file", "url", "type")}
+	case "record":
+		return &RecordSegment{File: data["file"], Url: data["url"], Extra: extraData(data, "file", "url")}
+	case "video":
+		return &VideoSegment{File: data["file"], Url: data["url"], Extra: extraData(data, "file", "url")}
+	case "reply":
+		if id, err := strconv.ParseInt(data["id"], 10, 32); err == nil {
+			return &ReplySegment{Id: int32(id), Extra: extraData(data, "id")}
+		}
+	case "forward":
+		return &ForwardSegment{Id: data["id"], Extra: extraData(data, "id")}
+	case "node":
+		if data["id"] != "" {
+			if id, err := strconv.ParseInt(data["id"], 10, 32); err == nil {
+				return &NodeSegment{Id: int32(id), Extra: extraData(data, "id")}
+			}
+			break
+		}
+		uin, _ := strconv.ParseInt(data["uin"], 10, 64)
+		return &NodeSegment{
+			Name:    data["name"],
+			Uin:     uin,
+			Content: ParseSegments(parseNodeContent(data["content"])),
+			Extra:   extraData(data, "name", "uin", "content"),
+		}
+	case "xml":
+		return &XmlSegment{Data: data["data"], Extra: extraData(data, "data")}
+	case "json":
+		return &JsonSegment{Data: data["data"], Extra: extraData(data, "data")}
+	}
+	return &UnknownSegment{
+		SegmentType: message.Type,
+		Data:        copyData(data),
+	}
+}
+
+// ParseSegments 解析消息段列表
+func ParseSegments(messageList []*onebot.Message) []Segment {
+	segments := make([]Segment, 0, len(messageList))
+	for _, message := range messageList {
+		if message == nil {
+			continue
+		}
+		segments = append(segments, ParseSegment(message))
+	}
+	return segments
+}
+
+// parseNodeContent 自定义节点的内容可能是 JSON 消息段数组，也可能是CQ码字符串
+func parseNodeContent(content string) []*onebot.Message {
+	if strings.HasPrefix(strings.TrimSpace(content), "[{") {
+		var messageList []*onebot.Message
+		if err := json.Unmarshal([]byte(content), &messageList); err == nil {
+			return messageList
+		}
+	}
+	return cqcode.Unmarshal(content)
+}
+
+// newMessage 构造消息段，kv 为键值对，值为空的键会被忽略
+func newMessage(messageType string, kv ...string) *onebot.Message {
+	data := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			data[kv[i]] = kv[i+1]
+		}
+	}
+	return &onebot.Message{
+		Type: messageType,
+		Data: data,
+	}
+}
+
+// extraData 除 known 以外的数据，没有时返回 nil
+func extraData(data map[string]string, known ...string) map[string]string {
+	var extra map[string]string
+	for k, v := range data {
+		if containsKey(known, k) {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]string)
+		}
+		extra[k] = v
+	}
+	return extra
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// withExtra 把 extra 合并到消息段数据中，已有的键优先
+func withExtra(message *onebot.Message, extra map[string]string) *onebot.Message {
+	for k, v := range extra {
+		if _, ok := message.Data[k]; !ok {
+			message.Data[k] = v
+		}
+	}
+	return message
+}
+
+func copyData(data map[string]string) map[string]string {
+	result := make(map[string]string, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,174 @@
+package pbbot
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// SplitOptions 长消息拆分发送的参数
+type SplitOptions struct {
+	MaxChars         int           // 每条消息最多的文字数（按字符计算），0 表示不限制
+	MaxSegments      int           // 每条消息最多的消息段数，0 表示不限制
+	Delay            time.Duration // 每条消息之间的发送间隔
+	ForwardThreshold int           // 拆分后的条数达到该值时改为发送一条合并转发消息，0 表示不使用合并转发
+	ForwardName      string        // 合并转发节点显示的名字，为空时使用机器人QQ号
+}
+
+var DefaultSplitOptions = &SplitOptions{
+	MaxChars:    4500,
+	MaxSegments: 50,
+	Delay:       500 * time.Millisecond,
+}
+
+// Split 按文字数和消息段数拆分消息，只拆分纯文本，不会拆开@、图片等消息段，也不会拆开多字节字符，优先在换行处拆分
+func (msg *Msg) Split(maxChars int, maxSegments int) []*Msg {
+	parts := make([]*Msg, 0)
+	current := NewMsg()
+	chars := 0
+	flush := func() {
+		if len(current.MessageList) == 0 {
+			return
+		}
+		parts = append(parts, current)
+		current = NewMsg()
+		chars = 0
+	}
+	segmentsFull := func() bool {
+		return maxSegments > 0 && len(current.MessageList) >= maxSegments
+	}
+	for _, message := range msg.MessageList {
+		if message.Type != "text" {
+			if segmentsFull() {
+				flush()
+			}
+			current.MessageList = append(current.MessageList, message)
+			continue
+		}
+		text := []rune(message.Data["text"])
+		for len(text) > 0 {
+			if segmentsFull() {
+				flush()
+			}
+			n := len(text)
+			if maxChars > 0 {
+				remain := maxChars - chars
+				if remain <= 0 {
+					flush()
+					continue
+				}
+				if n > remain {
+					n = splitPoint(text, remain)
+				}
+			}
+			current.Text(string(text[:n]))
+			chars += n
+			text = text[n:]
+			if len(text) > 0 {
+				// 在换行处拆开后，剩余文字从下一条开始
+				flush()
+			}
+		}
+	}
+	flush()
+	return parts
+}
+
+// splitPoint 在 limit 之内寻找最后一个换行，换行太靠前时直接在 limit 处拆分
+func splitPoint(text []rune, limit int) int {
+	for i := limit - 1; i >= limit/2; i-- {
+		if text[i] == '\n' {
+			return i + 1
+		}
+	}
+	return limit
+}
+
+// SendOption 发送消息的选项
+type SendOption func(options *sendOptions)
+
+type sendOptions struct {
+	split      *SplitOptions
+	messageIds *[]int32
+}
+
+// WithSplit 按 options 拆分消息并按顺序发送，options 为 nil 时使用 DefaultSplitOptions
+func WithSplit(options *SplitOptions) SendOption {
+	return func(o *sendOptions) {
+		if options == nil {
+			options = DefaultSplitOptions
+		}
+		o.split = options
+	}
+}
+
+// WithMessageIds 发送后把所有消息ID写入 ids，拆分发送中途出错时为已发送的消息ID
+func WithMessageIds(ids *[]int32) SendOption {
+	return func(o *sendOptions) {
+		o.messageIds = ids
+	}
+}
+
+// sendWithOptions 根据选项拆分并依次调用 send，发送间隔中 ctx 取消或连接断开时停止
+func (bot *Bot) sendWithOptions(ctx context.Context, msg *Msg, opts []SendOption, send func(part *Msg) (int32, error)) error {
+	options := &sendOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	messageIds := make([]int32, 0, 1)
+	defer func() {
+		if options.messageIds != nil {
+			*options.messageIds = messageIds
+		}
+	}()
+	if err := msg.Err(); err != nil {
+		return err
+	}
+	parts := []*Msg{msg}
+	if options.split != nil {
+		parts = bot.splitParts(msg, options.split)
+	}
+	for i, part := range parts {
+		if i > 0 && options.split.Delay > 0 {
+			if err := bot.sleep(ctx, options.split.Delay); err != nil {
+				return err
+			}
+		}
+		messageId, err := send(part)
+		if err != nil {
+			return err
+		}
+		messageIds = append(messageIds, messageId)
+	}
+	return nil
+}
+
+// splitParts 拆分消息，条数达到 ForwardThreshold 时合并为一条合并转发消息
+func (bot *Bot) splitParts(msg *Msg, options *SplitOptions) []*Msg {
+	parts := msg.Split(options.MaxChars, options.MaxSegments)
+	if options.ForwardThreshold <= 0 || len(parts) < options.ForwardThreshold {
+		return parts
+	}
+	name := options.ForwardName
+	if name == "" {
+		name = strconv.FormatInt(bot.BotId, 10)
+	}
+	forwardMsg := NewForwardMsg()
+	for _, part := range parts {
+		forwardMsg.CustomNode(name, bot.BotId, part)
+	}
+	return []*Msg{forwardMsg.Msg()}
+}
+
+func (bot *Bot) sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-bot.Session.Done():
+		return ErrDisconnected
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileStore 文件存储，每次修改后把全部数据写入临时文件再替换原文件，适合数据量不大的场景
+type FileStore struct {
+	*MemoryStore
+
+	path string
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.MemoryStore.data); err != nil {
+			return nil, err
+		}
+		s.MemoryStore.removeExpired()
+	}
+	s.MemoryStore.onChange = s.save
+	return s, nil
+}
+
+func (s *FileStore) save() error {
+	s.MemoryStore.removeExpired()
+	data, err := json.Marshal(s.MemoryStore.data)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
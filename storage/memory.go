@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	Value    []byte    `json:"value"`
+	ExpireAt time.Time `json:"expire_at,omitempty"`
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.ExpireAt.IsZero() && now.After(e.ExpireAt)
+}
+
+func newEntry(value []byte, ttl time.Duration) *entry {
+	e := &entry{Value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.ExpireAt = time.Now().Add(ttl)
+	}
+	return e
+}
+
+// MemoryStore 内存存储，重启后数据丢失
+type MemoryStore struct {
+	mu   sync.Mutex
+	data map[string]map[string]*entry
+
+	// onChange 数据修改后调用，调用时持有锁，用于 FileStore 持久化，返回错误时撤销修改
+	onChange func() error
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: make(map[string]map[string]*entry),
+	}
+}
+
+func (s *MemoryStore) Get(namespace string, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.get(namespace, key)
+	if e == nil {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), e.Value...), nil
+}
+
+func (s *MemoryStore) Set(namespace string, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.data[namespace][key]
+	s.set(namespace, key, newEntry(value, ttl))
+	return s.changed(namespace, key, old)
+}
+
+func (s *MemoryStore) Delete(namespace string, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.data[namespace][key]
+	s.remove(namespace, key)
+	return s.changed(namespace, key, old)
+}
+
+func (s *MemoryStore) Keys(namespace string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	keys := make([]string, 0, len(s.data[namespace]))
+	for key, e := range s.data[namespace] {
+		if !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *MemoryStore) Update(namespace string, key string, ttl time.Duration, fn func(old []byte) ([]byte, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var old []byte
+	if e := s.get(namespace, key); e != nil {
+		old = append([]byte(nil), e.Value...)
+	}
+	value, err := fn(old)
+	if err != nil {
+		return err
+	}
+	prev := s.data[namespace][key]
+	s.set(namespace, key, newEntry(value, ttl))
+	return s.changed(namespace, key, prev)
+}
+
+// get 返回未过期的记录，过期的记录会被删除
+func (s *MemoryStore) get(namespace string, key string) *entry {
+	e, ok := s.data[namespace][key]
+	if !ok {
+		return nil
+	}
+	if e.expired(time.Now()) {
+		delete(s.data[namespace], key)
+		return nil
+	}
+	return e
+}
+
+func (s *MemoryStore) set(namespace string, key string, e *entry) {
+	if s.data[namespace] == nil {
+		s.data[namespace] = make(map[string]*entry)
+	}
+	s.data[namespace][key] = e
+}
+
+func (s *MemoryStore) remove(namespace string, key string) {
+	delete(s.data[namespace], key)
+	if len(s.data[namespace]) == 0 {
+		delete(s.data, namespace)
+	}
+}
+
+// changed 持久化修改，失败时把 key 恢复为修改前的记录 old，old 为 nil 表示原来不存在
+func (s *MemoryStore) changed(namespace string, key string, old *entry) error {
+	if s.onChange == nil {
+		return nil
+	}
+	if err := s.onChange(); err != nil {
+		if old == nil {
+			s.remove(namespace, key)
+		} else {
+			s.set(namespace, key, old)
+		}
+		return err
+	}
+	return nil
+}
+
+// removeExpired 删除所有过期的记录
+func (s *MemoryStore) removeExpired() {
+	now := time.Now()
+	for namespace, entries := range s.data {
+		for key, e := range entries {
+			if e.expired(now) {
+				delete(entries, key)
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.data, namespace)
+		}
+	}
+}
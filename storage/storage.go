@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var ErrNotFound = errors.New("storage: key not found")
+
+// Store 键值存储，namespace 用于隔离不同机器人、群、用户或插件的数据，ttl 为 0 表示永不过期
+type Store interface {
+	Get(namespace string, key string) ([]byte, error)
+	Set(namespace string, key string, value []byte, ttl time.Duration) error
+	Delete(namespace string, key string) error
+	Keys(namespace string) ([]string, error)
+	// Update 原子地读取并修改，fn 的 old 在键不存在时为 nil，fn 返回错误时不修改
+	Update(namespace string, key string, ttl time.Duration, fn func(old []byte) ([]byte, error)) error
+}
+
+func BotNamespace(botId int64) string {
+	return "bot:" + strconv.FormatInt(botId, 10)
+}
+
+func GroupNamespace(botId int64, groupId int64) string {
+	return BotNamespace(botId) + "/group:" + strconv.FormatInt(groupId, 10)
+}
+
+func UserNamespace(botId int64, userId int64) string {
+	return BotNamespace(botId) + "/user:" + strconv.FormatInt(userId, 10)
+}
+
+// Bucket 绑定了 namespace 的 Store
+type Bucket struct {
+	Store     Store
+	Namespace string
+}
+
+func NewBucket(store Store, namespace string) *Bucket {
+	return &Bucket{
+		Store:     store,
+		Namespace: namespace,
+	}
+}
+
+// Sub 子命名空间，例如插件在群命名空间下的数据
+func (b *Bucket) Sub(name string) *Bucket {
+	return NewBucket(b.Store, b.Namespace+"/"+name)
+}
+
+func (b *Bucket) Get(key string) ([]byte, error) {
+	return b.Store.Get(b.Namespace, key)
+}
+
+func (b *Bucket) Set(key string, value []byte, ttl time.Duration) error {
+	return b.Store.Set(b.Namespace, key, value, ttl)
+}
+
+func (b *Bucket) Delete(key string) error {
+	return b.Store.Delete(b.Namespace, key)
+}
+
+func (b *Bucket) Keys() ([]string, error) {
+	return b.Store.Keys(b.Namespace)
+}
+
+func (b *Bucket) Update(key string, ttl time.Duration, fn func(old []byte) ([]byte, error)) error {
+	return b.Store.Update(b.Namespace, key, ttl, fn)
+}
+
+// GetJSON 读取并反序列化，键不存在时返回 ErrNotFound
+func (b *Bucket) GetJSON(key string, v interface{}) error {
+	data, err := b.Get(key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (b *Bucket) SetJSON(key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Set(key, data, ttl)
+}
+
+// Incr 原子地增加计数器并返回新值
+func (b *Bucket) Incr(key string, delta int64, ttl time.Duration) (int64, error) {
+	var result int64
+	err := b.Update(key, ttl, func(old []byte) ([]byte, error) {
+		if old != nil {
+			n, err := strconv.ParseInt(string(old), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			result = n
+		}
+		result += delta
+		return []byte(strconv.FormatInt(result, 10)), nil
+	})
+	return result, err
+}
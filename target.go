@@ -0,0 +1,87 @@
+package pbbot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// Target 消息发送目标，通过 Group、User、TempFromGroup 构造
+type Target struct {
+	MessageType string `json:"message_type"`
+	UserId      int64  `json:"user_id,omitempty"`
+	GroupId     int64  `json:"group_id,omitempty"`
+}
+
+// Group 群聊
+func Group(groupId int64) Target {
+	return Target{
+		MessageType: MessageTypeGroup,
+		GroupId:     groupId,
+	}
+}
+
+// User 私聊
+func User(userId int64) Target {
+	return Target{
+		MessageType: MessageTypePrivate,
+		UserId:      userId,
+	}
+}
+
+// TempFromGroup 通过群发起的临时会话
+func TempFromGroup(groupId int64, userId int64) Target {
+	return Target{
+		MessageType: MessageTypePrivate,
+		UserId:      userId,
+		GroupId:     groupId,
+	}
+}
+
+func (target Target) String() string {
+	switch {
+	case target.MessageType == MessageTypeGroup:
+		return fmt.Sprintf("group:%d", target.GroupId)
+	case target.GroupId != 0:
+		return fmt.Sprintf("temp:%d:%d", target.GroupId, target.UserId)
+	default:
+		return fmt.Sprintf("private:%d", target.UserId)
+	}
+}
+
+// Send 根据 target 发送私聊、群聊或临时会话消息，可以通过 WithSplit 拆分发送，拆分时返回最后一条消息的响应
+func (bot *Bot) Send(ctx context.Context, target Target, msg *Msg, opts ...SendOption) (*onebot.SendMsgResp, error) {
+	var resp *onebot.SendMsgResp
+	err := bot.sendWithOptions(ctx, msg, opts, func(part *Msg) (int32, error) {
+		var err error
+		resp, err = bot.send(ctx, target, part)
+		return resp.GetMessageId(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (bot *Bot) send(ctx context.Context, target Target, msg *Msg) (*onebot.SendMsgResp, error) {
+	if err := msg.Err(); err != nil {
+		return nil, err
+	}
+	if resp, err := bot.sendFrameAndWaitContext(ctx, &onebot.Frame{
+		FrameType: onebot.Frame_TSendMsgReq,
+		Data: &onebot.Frame_SendMsgReq{
+			SendMsgReq: &onebot.SendMsgReq{
+				MessageType: target.MessageType,
+				UserId:      target.UserId,
+				GroupId:     target.GroupId,
+				Message:     msg.MessageList,
+			},
+		},
+	}); err != nil {
+		return nil, err
+	} else {
+		bot.recordSent(target, msg, resp.GetSendMsgResp().GetMessageId())
+		return resp.GetSendMsgResp(), nil
+	}
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/admin"
+)
+
+func TestAdminHandler(t *testing.T) {
+	const botId = 47001
+	a := admin.New(0)
+	a.Install()
+	client := newFakeBot(t, botId, nil)
+	if _, err := client.bot.GetLoginInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	do := func(method string, path string, v interface{}) int {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		a.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		if v != nil && recorder.Code == http.StatusOK {
+			if ct := recorder.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Errorf("unexpected content type %q", ct)
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return recorder.Code
+	}
+
+	var bots []map[string]interface{}
+	if code := do(http.MethodGet, "/bots", &bots); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	var info map[string]interface{}
+	for _, bot := range bots {
+		if bot["bot_id"] == float64(botId) {
+			info = bot
+		}
+	}
+	if info == nil || info["remote_addr"] == "" || info["encoding"] == "" || info["connected_at"] == nil {
+		t.Errorf("unexpected bot info: %v", bots)
+	}
+
+	var frames []struct {
+		Direction string                 `json:"direction"`
+		FrameType string                 `json:"frame_type"`
+		Echo      string                 `json:"echo"`
+		Frame     map[string]interface{} `json:"frame"`
+	}
+	if code := do(http.MethodGet, "/bots/47001/frames", &frames); code != http.StatusOK {
+		t.Fatalf("unexpected status %d", code)
+	}
+	out, in := -1, -1
+	for i := range frames {
+		switch frames[i].FrameType {
+		case "TGetLoginInfoReq":
+			out = i
+		case "TGetLoginInfoResp":
+			in = i
+		}
+	}
+	if out < 0 || in < 0 || frames[out].Direction != "out" || frames[in].Direction != "in" ||
+		frames[out].Echo == "" || frames[out].Echo != frames[in].Echo || frames[out].Frame == nil {
+		t.Errorf("unexpected frames: %+v", frames)
+	}
+
+	errorCases := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{http.MethodGet, "/unknown", http.StatusNotFound},
+		{http.MethodPost, "/bots", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/bots/abc/frames", http.StatusBadRequest},
+		{http.MethodGet, "/bots/47001/disconnect", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/bots/1/disconnect", http.StatusNotFound},
+	}
+	for _, c := range errorCases {
+		if code := do(c.method, c.path, nil); code != c.code {
+			t.Errorf("%s %s: got %d, want %d", c.method, c.path, code, c.code)
+		}
+	}
+
+	// 断开连接后从 Bots 中删除
+	if code := do(http.MethodPost, "/bots/47001/disconnect", nil); code != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", code)
+	}
+	waitFor(t, func() bool {
+		_, ok := pbbot.GetBot(botId)
+		return !ok && len(a.Frames(botId)) == 0
+	})
+	for _, bot := range pbbot.ListBots() {
+		if bot.BotId == botId {
+			t.Error("disconnected bot should not be listed")
+		}
+	}
+}
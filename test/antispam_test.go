@@ -0,0 +1,108 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/antispam"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+func TestAntispam(t *testing.T) {
+	client := newFakeBot(t, 39001, messageIdResponder())
+	guard := antispam.NewGuard(antispam.Config{
+		Enabled:     true,
+		Window:      time.Second,
+		MaxMessages: 3,
+		Actions:     antispam.ActionDrop | antispam.ActionWarn | antispam.ActionBan,
+		BanDuration: time.Minute,
+		WarnMessage: "flood",
+		AllowAdmins: true,
+		Allowlist:   map[int64]bool{9: true},
+	})
+	middleware := guard.Middleware()
+	passed := func(groupId int64, userId int64, role string, text string) bool {
+		event := groupMessage(groupId, userId, role, text)
+		event.MessageId = int32(userId)
+		ok := false
+		middleware(pbbot.NewGroupMessageContext(client.bot, event), func() { ok = true })
+		return ok
+	}
+	countRequests := func(frameType onebot.Frame_FrameType) int {
+		return len(sendRequestsOf(client.Requests(), frameType))
+	}
+
+	// 刷屏：窗口内第4条消息被丢弃，警告和禁言只执行一次
+	for i := 0; i < 3; i++ {
+		if !passed(100, 1, pbbot.RoleMember, fmt.Sprint(i)) {
+			t.Fatalf("message %d should pass", i)
+		}
+	}
+	if passed(100, 1, pbbot.RoleMember, "3") || passed(100, 1, pbbot.RoleMember, "4") {
+		t.Error("flood message should be dropped")
+	}
+	waitFor(t, func() bool {
+		return countRequests(onebot.Frame_TSendMsgReq) == 1 && countRequests(onebot.Frame_TSetGroupBanReq) == 1
+	})
+	ban := sendRequestsOf(client.Requests(), onebot.Frame_TSetGroupBanReq)[0].GetSetGroupBanReq()
+	if ban.GroupId != 100 || ban.UserId != 1 || ban.Duration != 60 {
+		t.Errorf("unexpected ban: %+v", ban)
+	}
+	warn := pbbot.ParseMsg(sendRequestsOf(client.Requests(), onebot.Frame_TSendMsgReq)[0].GetSendMsgReq().Message)
+	if !warn.MentionsMe(1) || warn.PlainText() != " flood" {
+		t.Errorf("unexpected warning: %+v", warn.MessageList)
+	}
+	// 不同用户、不同群分别计数
+	if !passed(100, 2, pbbot.RoleMember, "0") || !passed(101, 1, pbbot.RoleMember, "0") {
+		t.Error("flood should be counted per user and group")
+	}
+
+	// 管理员和白名单用户不检测
+	for i := 0; i < 5; i++ {
+		if !passed(100, 3, pbbot.RoleAdmin, "admin") || !passed(100, 9, pbbot.RoleMember, "allow") {
+			t.Fatal("admin and allowlisted user should not be checked")
+		}
+	}
+
+	// 重复消息：群单独配置，没有 AllowAdmins 时管理员也会检测，只撤回不丢弃
+	guard.SetGroupConfig(200, antispam.Config{
+		Enabled:       true,
+		Window:        50 * time.Millisecond,
+		MaxDuplicates: 2,
+		Actions:       antispam.ActionRecall,
+	})
+	if !passed(200, 3, pbbot.RoleAdmin, "spam") || !passed(200, 3, pbbot.RoleAdmin, "other") || !passed(200, 3, pbbot.RoleAdmin, "spam") {
+		t.Fatal("messages under limit should pass")
+	}
+	if !passed(200, 3, pbbot.RoleAdmin, "spam") {
+		t.Error("message should not be dropped without ActionDrop")
+	}
+	waitFor(t, func() bool { return countRequests(onebot.Frame_TDeleteMsgReq) == 1 })
+	if id := sendRequestsOf(client.Requests(), onebot.Frame_TDeleteMsgReq)[0].GetDeleteMsgReq().MessageId; id != 3 {
+		t.Errorf("unexpected recalled message: %d", id)
+	}
+	// 窗口过期后重新计数
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		passed(200, 3, pbbot.RoleAdmin, "spam")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := countRequests(onebot.Frame_TDeleteMsgReq); n != 1 {
+		t.Errorf("duplicates should expire with window, recalls: %d", n)
+	}
+	if countRequests(onebot.Frame_TSendMsgReq) != 1 || countRequests(onebot.Frame_TSetGroupBanReq) != 1 {
+		t.Error("warning and ban should only be sent once per window")
+	}
+}
+
+func sendRequestsOf(requests []*onebot.Frame, frameType onebot.Frame_FrameType) []*onebot.Frame {
+	result := make([]*onebot.Frame, 0)
+	for _, req := range requests {
+		if req.FrameType == frameType {
+			result = append(result, req)
+		}
+	}
+	return result
+}
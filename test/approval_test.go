@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/approval"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+func TestApprovalPolicy(t *testing.T) {
+	const botId = 40001
+	var failing int32
+	pbbot.UseApiInterceptor(func(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+		if bot.BotId == botId && req.GetSetGroupAddRequestReq() != nil && atomic.LoadInt32(&failing) == 1 {
+			return nil, errors.New("set group add request failed")
+		}
+		return next(ctx)
+	})
+	client := newFakeBot(t, botId, nil)
+	bot := client.bot
+
+	notified := make([]*approval.Request, 0)
+	policy := approval.NewPolicy(approval.OutcomeNotifyAdmin,
+		&approval.Rule{Name: "friend", RequestType: approval.RequestTypeFriend, Keywords: []string{"hello"}, Outcome: approval.OutcomeApprove, Reason: "remark"},
+		&approval.Rule{Name: "answer", RequestType: approval.RequestTypeGroup, MatchAnswer: true, Keywords: []string{"wrong"}, Outcome: approval.OutcomeReject, Reason: "bad answer"},
+	)
+	policy.Blacklist[666] = true
+	policy.Notify = func(bot *pbbot.Bot, req *approval.Request) {
+		notified = append(notified, req)
+	}
+
+	// 自动同意
+	policy.HandleFriendRequest(bot, &onebot.FriendRequestEvent{UserId: 1, Comment: "hello", Flag: "f1"})
+	// 规则拒绝和黑名单拒绝
+	policy.HandleGroupRequest(bot, &onebot.GroupRequestEvent{SubType: approval.SubTypeAdd, GroupId: 100, UserId: 2, Comment: "问题：1+1\n答案：wrong", Flag: "g1"})
+	policy.HandleFriendRequest(bot, &onebot.FriendRequestEvent{UserId: 666, Comment: "hello", Flag: "f2"})
+	friendRequests := sendRequestsOf(client.Requests(), onebot.Frame_TSetFriendAddRequestReq)
+	groupRequests := sendRequestsOf(client.Requests(), onebot.Frame_TSetGroupAddRequestReq)
+	if len(friendRequests) != 2 || len(groupRequests) != 1 {
+		t.Fatalf("unexpected requests, friend: %d, group: %d", len(friendRequests), len(groupRequests))
+	}
+	approve := friendRequests[0].GetSetFriendAddRequestReq()
+	if approve.GetFlag() != "f1" || !approve.GetApprove() || approve.GetRemark() != "remark" {
+		t.Errorf("unexpected approve request: %+v", approve)
+	}
+	reject := groupRequests[0].GetSetGroupAddRequestReq()
+	if reject.GetFlag() != "g1" || reject.GetApprove() || reject.GetReason() != "bad answer" || reject.GetSubType() != approval.SubTypeAdd {
+		t.Errorf("unexpected reject request: %+v", reject)
+	}
+	blacklisted := friendRequests[1].GetSetFriendAddRequestReq()
+	if blacklisted.GetFlag() != "f2" || blacklisted.GetApprove() {
+		t.Errorf("blacklisted user should be rejected: %+v", blacklisted)
+	}
+	if len(policy.Pending()) != 0 || len(notified) != 0 {
+		t.Fatal("handled requests should not be pending")
+	}
+
+	// 没有匹配的规则时等待管理员处理
+	policy.HandleGroupRequest(bot, &onebot.GroupRequestEvent{SubType: approval.SubTypeInvite, GroupId: 200, UserId: 3, Flag: "g2"})
+	if len(notified) != 1 || notified[0].Flag != "g2" || len(policy.Pending()) != 1 {
+		t.Fatalf("request should be pending, notified: %d", len(notified))
+	}
+	// 调用API失败时保留请求
+	atomic.StoreInt32(&failing, 1)
+	if err := policy.Resolve(bot, "g2", true, ""); err == nil {
+		t.Error("expected resolve error")
+	}
+	if len(policy.Pending()) != 1 {
+		t.Fatal("failed request should stay pending")
+	}
+	atomic.StoreInt32(&failing, 0)
+	if err := policy.Resolve(bot, "g2", true, "ignored"); err != nil {
+		t.Fatal(err)
+	}
+	if len(policy.Pending()) != 0 {
+		t.Error("resolved request should be removed")
+	}
+	groupRequests = sendRequestsOf(client.Requests(), onebot.Frame_TSetGroupAddRequestReq)
+	resolved := groupRequests[len(groupRequests)-1].GetSetGroupAddRequestReq()
+	if resolved.GetFlag() != "g2" || !resolved.GetApprove() || resolved.GetReason() != "" || resolved.GetSubType() != approval.SubTypeInvite {
+		t.Errorf("unexpected resolve request: %+v", resolved)
+	}
+	if err := policy.Resolve(bot, "g2", true, ""); err == nil {
+		t.Error("resolving twice should fail")
+	}
+}
+
+func TestApprovalPendingLimit(t *testing.T) {
+	client := newFakeBot(t, 40002, nil)
+	policy := approval.NewPolicy(approval.OutcomeNotifyAdmin)
+	policy.Notify = func(bot *pbbot.Bot, req *approval.Request) {}
+	policy.MaxPending = 2
+	flags := func() []string {
+		result := make([]string, 0)
+		for _, req := range policy.Pending() {
+			result = append(result, req.Flag)
+		}
+		return result
+	}
+
+	// 超过 MaxPending 时删除最早的请求
+	for _, flag := range []string{"p1", "p2", "p3"} {
+		policy.HandleFriendRequest(client.bot, &onebot.FriendRequestEvent{UserId: 1, Flag: flag})
+	}
+	if got := flags(); len(got) != 2 || got[0] != "p2" || got[1] != "p3" {
+		t.Fatalf("unexpected pending requests: %v", got)
+	}
+	if err := policy.Resolve(client.bot, "p1", true, ""); err == nil {
+		t.Error("evicted request should not be resolved")
+	}
+
+	// 超过 PendingTTL 后过期
+	policy.PendingTTL = 50 * time.Millisecond
+	time.Sleep(100 * time.Millisecond)
+	if got := flags(); len(got) != 0 {
+		t.Errorf("expired requests should be removed: %v", got)
+	}
+	if err := policy.Resolve(client.bot, "p3", true, ""); err == nil {
+		t.Error("expired request should not be resolved")
+	}
+	if len(sendRequestsOf(client.Requests(), onebot.Frame_TSetFriendAddRequestReq)) != 0 {
+		t.Error("evicted and expired requests should not call api")
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+func TestCacheUpdate(t *testing.T) {
+	const botId = 34001
+	// 群1的成员列表加载失败，不影响其他群
+	pbbot.UseApiInterceptor(func(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+		if bot.BotId == botId && req.GetGetGroupMemberListReq().GetGroupId() == 1 {
+			return nil, errors.New("member list unavailable")
+		}
+		return next(ctx)
+	})
+	client := newFakeBot(t, botId, func(req *onebot.Frame) *onebot.Frame {
+		switch req.FrameType {
+		case onebot.Frame_TGetFriendListReq:
+			return &onebot.Frame{Data: &onebot.Frame_GetFriendListResp{GetFriendListResp: &onebot.GetFriendListResp{
+				Friend: []*onebot.GetFriendListResp_Friend{{UserId: 1000}},
+			}}}
+		case onebot.Frame_TGetGroupListReq:
+			return &onebot.Frame{Data: &onebot.Frame_GetGroupListResp{GetGroupListResp: &onebot.GetGroupListResp{
+				Group: []*onebot.GetGroupListResp_Group{{GroupId: 1, MemberCount: 5}, {GroupId: 2, MemberCount: 2}},
+			}}}
+		case onebot.Frame_TGetGroupMemberListReq:
+			return &onebot.Frame{Data: &onebot.Frame_GetGroupMemberListResp{GetGroupMemberListResp: &onebot.GetGroupMemberListResp{
+				GroupMember: []*onebot.GetGroupMemberListResp_GroupMember{
+					{GroupId: 2, UserId: 3000, Nickname: "old", Role: pbbot.RoleMember},
+					{GroupId: 2, UserId: botId, Role: pbbot.RoleAdmin},
+				},
+			}}}
+		case onebot.Frame_TGetGroupMemberInfoReq:
+			return &onebot.Frame{Data: &onebot.Frame_GetGroupMemberInfoResp{GetGroupMemberInfoResp: &onebot.GetGroupMemberInfoResp{
+				GroupId:  2,
+				UserId:   req.GetGetGroupMemberInfoReq().UserId,
+				Nickname: "new",
+				Role:     pbbot.RoleMember,
+			}}}
+		}
+		return &onebot.Frame{}
+	})
+	cache := client.bot.Cache
+
+	waitFor(t, func() bool { return len(cache.Members(2)) == 2 })
+	if _, ok := cache.Group(1); !ok || !cache.IsFriend(1000) || !cache.IsAdmin(2, botId) {
+		t.Fatal("cache should be loaded even if one group fails")
+	}
+
+	memberCount := func() int32 {
+		group, _ := cache.Group(2)
+		return group.GetMemberCount()
+	}
+
+	client.push(&onebot.Frame{
+		FrameType: onebot.Frame_TGroupIncreaseNoticeEvent,
+		Data:      &onebot.Frame_GroupIncreaseNoticeEvent{GroupIncreaseNoticeEvent: &onebot.GroupIncreaseNoticeEvent{GroupId: 2, UserId: 4000}},
+	})
+	waitFor(t, func() bool { return cache.MemberCard(2, 4000) == "new" })
+	if memberCount() != 3 {
+		t.Errorf("member count should increase, got %d", memberCount())
+	}
+
+	client.push(&onebot.Frame{
+		FrameType: onebot.Frame_TGroupDecreaseNoticeEvent,
+		Data:      &onebot.Frame_GroupDecreaseNoticeEvent{GroupDecreaseNoticeEvent: &onebot.GroupDecreaseNoticeEvent{GroupId: 2, UserId: 3000, SubType: "leave"}},
+	})
+	waitFor(t, func() bool { return !cache.InGroup(2, 3000) })
+	if memberCount() != 2 {
+		t.Errorf("member count should decrease, got %d", memberCount())
+	}
+
+	// 群名片只从群消息的发送者信息更新
+	client.push(&onebot.Frame{
+		FrameType: onebot.Frame_TGroupMessageEvent,
+		Data: &onebot.Frame_GroupMessageEvent{GroupMessageEvent: &onebot.GroupMessageEvent{
+			GroupId: 2,
+			UserId:  4000,
+			Sender:  &onebot.GroupMessageEvent_Sender{UserId: 4000, Nickname: "new", Card: "card"},
+		}},
+	})
+	waitFor(t, func() bool { return cache.MemberCard(2, 4000) == "card" })
+
+	client.push(&onebot.Frame{
+		FrameType: onebot.Frame_TGroupDecreaseNoticeEvent,
+		Data:      &onebot.Frame_GroupDecreaseNoticeEvent{GroupDecreaseNoticeEvent: &onebot.GroupDecreaseNoticeEvent{GroupId: 2, UserId: botId, SubType: "kick_me"}},
+	})
+	waitFor(t, func() bool {
+		_, ok := cache.Group(2)
+		return !ok && len(cache.Members(2)) == 0
+	})
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+func groupMessage(groupId int64, userId int64, role string, text string) *onebot.GroupMessageEvent {
+	return &onebot.GroupMessageEvent{
+		GroupId: groupId,
+		UserId:  userId,
+		Sender:  &onebot.GroupMessageEvent_Sender{UserId: userId, Role: role},
+		Message: pbbot.NewMsg().Text(text).MessageList,
+	}
+}
+
+func TestPermission(t *testing.T) {
+	const botId = 37001
+	client := newFakeBot(t, botId, func(req *onebot.Frame) *onebot.Frame {
+		switch req.FrameType {
+		case onebot.Frame_TGetGroupListReq:
+			return &onebot.Frame{Data: &onebot.Frame_GetGroupListResp{GetGroupListResp: &onebot.GetGroupListResp{
+				Group: []*onebot.GetGroupListResp_Group{{GroupId: 100}},
+			}}}
+		case onebot.Frame_TGetGroupMemberListReq:
+			return &onebot.Frame{Data: &onebot.Frame_GetGroupMemberListResp{GetGroupMemberListResp: &onebot.GetGroupMemberListResp{
+				GroupMember: []*onebot.GetGroupMemberListResp_GroupMember{{GroupId: 100, UserId: 5, Role: pbbot.RoleAdmin}},
+			}}}
+		}
+		return &onebot.Frame{}
+	})
+	bot := client.bot
+	waitFor(t, func() bool { return bot.Cache.IsAdmin(100, 5) })
+
+	permission := func(event *onebot.GroupMessageEvent) pbbot.Permission {
+		return pbbot.NewGroupMessageContext(bot, event).Permission()
+	}
+	cases := []struct {
+		name  string
+		event *onebot.GroupMessageEvent
+		want  pbbot.Permission
+	}{
+		{"member", groupMessage(100, 1, pbbot.RoleMember, ""), pbbot.PermissionEveryone},
+		{"admin", groupMessage(100, 2, pbbot.RoleAdmin, ""), pbbot.PermissionGroupAdmin},
+		{"owner", groupMessage(100, 3, pbbot.RoleOwner, ""), pbbot.PermissionGroupOwner},
+		{"role from cache", groupMessage(100, 5, "", ""), pbbot.PermissionGroupAdmin},
+	}
+	for _, c := range cases {
+		if got := permission(c.event); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+
+	if err := pbbot.GrantPermission(botId, 100, 1, pbbot.PermissionGroupOwner); err != nil {
+		t.Fatal(err)
+	}
+	ctx := pbbot.NewGroupMessageContext(bot, groupMessage(100, 1, pbbot.RoleMember, ""))
+	if ctx.Permission() != pbbot.PermissionGroupOwner || !ctx.HasPermission(pbbot.PermissionGroupAdmin) || ctx.HasPermission(pbbot.PermissionSuperUser) {
+		t.Errorf("granted permission not applied: %s", ctx.Permission())
+	}
+	// 授予的权限低于群角色时取群角色
+	if err := pbbot.GrantPermission(botId, 100, 3, pbbot.PermissionGroupAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if got := permission(groupMessage(100, 3, pbbot.RoleOwner, "")); got != pbbot.PermissionGroupOwner {
+		t.Errorf("owner should keep higher role, got %s", got)
+	}
+	if err := pbbot.RevokePermission(botId, 100, 1); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.HasPermission(pbbot.PermissionGroupAdmin) {
+		t.Error("revoked permission should not be applied")
+	}
+	// 授予的权限只在对应的群生效
+	if got := permission(groupMessage(101, 3, pbbot.RoleMember, "")); got != pbbot.PermissionEveryone {
+		t.Errorf("grant should be scoped to group, got %s", got)
+	}
+
+	private := pbbot.NewPrivateMessageContext(bot, &onebot.PrivateMessageEvent{UserId: 2})
+	if private.Permission() != pbbot.PermissionEveryone {
+		t.Errorf("private message should have no group permission, got %s", private.Permission())
+	}
+	pbbot.SuperUsers[2] = true
+	defer delete(pbbot.SuperUsers, 2)
+	if private.Permission() != pbbot.PermissionSuperUser || !private.HasPermission(pbbot.PermissionGroupOwner) {
+		t.Errorf("super user should have all permissions, got %s", private.Permission())
+	}
+}
+
+func TestCommandRouting(t *testing.T) {
+	logger := newRecordLogger()
+	client := newFakeBotWithLogger(t, 37002, logger, messageIdResponder())
+
+	calls := make(chan []string, 10)
+	record := func(name string) func(ctx *pbbot.MessageContext, args []string) {
+		return func(ctx *pbbot.MessageContext, args []string) {
+			calls <- append([]string{name}, args...)
+		}
+	}
+	pbbot.RegisterCommand(&pbbot.Command{Name: "echo37", Aliases: []string{"e37"}, Handler: record("echo37")})
+	pbbot.RegisterCommand(&pbbot.Command{Name: "ban37", Permission: pbbot.PermissionGroupAdmin, Handler: record("ban37")})
+	pbbot.RegisterCommand(&pbbot.Command{Name: "group37", GroupOnly: true, Handler: record("group37")})
+	defer func() {
+		pbbot.UnregisterCommand("echo37")
+		pbbot.UnregisterCommand("ban37")
+		pbbot.UnregisterCommand("group37")
+	}()
+	expectCall := func(want ...string) {
+		t.Helper()
+		got := <-calls
+		if len(got) != len(want) {
+			t.Fatalf("got call %q, want %q", got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("got call %q, want %q", got, want)
+			}
+		}
+	}
+	pushGroup := func(event *onebot.GroupMessageEvent) {
+		client.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{GroupMessageEvent: event}})
+	}
+	pushPrivate := func(userId int64, text string) {
+		client.push(&onebot.Frame{FrameType: onebot.Frame_TPrivateMessageEvent, Data: &onebot.Frame_PrivateMessageEvent{PrivateMessageEvent: &onebot.PrivateMessageEvent{
+			UserId:  userId,
+			Message: pbbot.NewMsg().Text(text).MessageList,
+		}}})
+	}
+
+	// 别名和参数
+	pushGroup(groupMessage(100, 1, pbbot.RoleMember, "/e37 a  b"))
+	expectCall("echo37", "a", "b")
+
+	// 权限不足时回复提示，不调用命令
+	pushGroup(groupMessage(100, 1, pbbot.RoleMember, "/ban37 2"))
+	waitFor(t, func() bool { return len(sendRequests(client.Requests())) == 1 })
+	denied := sendRequests(client.Requests())[0].GetSendMsgReq()
+	if pbbot.ParseMsg(denied.Message).PlainText() != "权限不足" || denied.GroupId != 100 {
+		t.Errorf("unexpected denied reply: %+v", denied)
+	}
+	if _, ok := logger.Find("permission denied"); !ok {
+		t.Error("permission denied should be logged")
+	}
+	pushGroup(groupMessage(100, 2, pbbot.RoleAdmin, "/ban37 1"))
+	expectCall("ban37", "1")
+	if entry, ok := logger.Find("permission granted"); !ok || entry.level != pbbot.LevelDebug {
+		t.Errorf("permission granted should be logged at debug: %+v", entry)
+	}
+
+	// 仅群聊命令在私聊中不执行
+	pushPrivate(3, "/group37")
+	waitFor(t, func() bool {
+		entry, ok := logger.Find("group only command in private message")
+		return ok && entry.level == pbbot.LevelDebug
+	})
+	pushGroup(groupMessage(100, 3, pbbot.RoleMember, "/group37"))
+	expectCall("group37")
+
+	// 没有前缀或未注册的命令不匹配
+	pushGroup(groupMessage(100, 1, pbbot.RoleMember, "echo37"))
+	pushGroup(groupMessage(100, 1, pbbot.RoleMember, "/unknown37"))
+	pushPrivate(3, "/echo37 done")
+	expectCall("echo37", "done")
+	select {
+	case call := <-calls:
+		t.Errorf("unexpected call %q", call)
+	default:
+	}
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+func TestMessageContextReply(t *testing.T) {
+	client := newFakeBot(t, 31001, messageIdResponder())
+	bot := client.bot
+
+	groupCtx := pbbot.NewGroupMessageContext(bot, &onebot.GroupMessageEvent{
+		MessageId: 10,
+		GroupId:   100,
+		UserId:    200,
+		Message:   pbbot.NewMsg().At(31001).Text(" hi").MessageList,
+	})
+	if !groupCtx.MentionsMe() {
+		t.Error("group message should mention bot")
+	}
+	if _, err := groupCtx.ReplyAt(pbbot.NewMsg().Text("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := groupCtx.ReplyQuote(pbbot.NewMsg().Text("quote")); err != nil {
+		t.Fatal(err)
+	}
+
+	privateCtx := pbbot.NewPrivateMessageContext(bot, &onebot.PrivateMessageEvent{
+		MessageId: 11,
+		UserId:    300,
+		Message:   pbbot.NewMsg().Text("hi").MessageList,
+	})
+	if !privateCtx.MentionsMe() {
+		t.Error("private message should always mention bot")
+	}
+	if _, err := privateCtx.ReplyAt(pbbot.NewMsg().Text("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := privateCtx.BanSender(60); err != pbbot.ErrNotGroupMessage {
+		t.Errorf("expected ErrNotGroupMessage, got %v", err)
+	}
+
+	requests := sendRequests(client.Requests())
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 send requests, got %d", len(requests))
+	}
+
+	at := requests[0].GetSendMsgReq()
+	if at.MessageType != pbbot.MessageTypeGroup || at.GroupId != 100 {
+		t.Errorf("unexpected ReplyAt target: %+v", at)
+	}
+	atMsg := pbbot.ParseMsg(at.Message)
+	if !atMsg.MentionsMe(200) || atMsg.PlainText() != " hello" {
+		t.Errorf("unexpected ReplyAt message: %+v", at.Message)
+	}
+
+	quote := pbbot.ParseMsg(requests[1].GetSendMsgReq().Message)
+	if reply := quote.GetReply(); reply == nil || reply.Id != 10 || quote.PlainText() != "quote" {
+		t.Errorf("unexpected ReplyQuote message: %+v", requests[1].GetSendMsgReq().Message)
+	}
+
+	private := requests[2].GetSendMsgReq()
+	if private.MessageType != pbbot.MessageTypePrivate || private.UserId != 300 || len(private.Message) != 1 {
+		t.Errorf("private ReplyAt should not at sender: %+v", private)
+	}
+}
@@ -0,0 +1,40 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/cqcode"
+)
+
+func TestCQCode(t *testing.T) {
+	str := "[CQ:at,qq=123]hi &#91;1&#93; &amp; [CQ:share,title=a&#44;b,url=http://x/?a=1&amp;b=2]"
+	messageList := cqcode.Unmarshal(str)
+	if len(messageList) != 3 {
+		t.Fatalf("unexpected message count: %+v", messageList)
+	}
+	if messageList[0].Type != "at" || messageList[0].Data["qq"] != "123" {
+		t.Errorf("unexpected at: %+v", messageList[0])
+	}
+	if messageList[1].Data["text"] != "hi [1] & " {
+		t.Errorf("unexpected text: %+v", messageList[1])
+	}
+	if messageList[2].Data["title"] != "a,b" || messageList[2].Data["url"] != "http://x/?a=1&b=2" {
+		t.Errorf("unexpected share: %+v", messageList[2])
+	}
+	if result := cqcode.Marshal(messageList); result != str {
+		t.Errorf("round trip failed: %s", result)
+	}
+
+	if messageList := cqcode.Unmarshal("[CQ:bad [CQ:face,id=1"); len(messageList) != 1 || messageList[0].Type != "text" {
+		t.Errorf("unexpected malformed result: %+v", messageList)
+	}
+
+	msg := pbbot.NewMsg().FromCQ("[CQ:face,id=1]", true)
+	if len(msg.MessageList) != 1 || msg.PlainText() != "[CQ:face,id=1]" {
+		t.Errorf("autoEscape not honored: %+v", msg.MessageList)
+	}
+	if cq := msg.ToCQ(); cq != "&#91;CQ:face,id=1&#93;" {
+		t.Errorf("unexpected escaped text: %s", cq)
+	}
+}
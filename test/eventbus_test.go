@@ -0,0 +1,103 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/eventbus"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+func TestEventBus(t *testing.T) {
+	for _, encoding := range []string{eventbus.EncodingProtobuf, eventbus.EncodingJSON} {
+		sink := eventbus.NewMemorySink(10)
+		received := make(chan *eventbus.Event, 1)
+		sink.Subscribe(func(event *eventbus.Event) {
+			received <- event
+		})
+		publisher := eventbus.NewPublisher(sink, &eventbus.Options{Encoding: encoding})
+		bot := &pbbot.Bot{BotId: 123, Logger: pbbot.DefaultLogger}
+		err := publisher.Publish(bot, &onebot.Frame{
+			BotId:     123,
+			FrameType: onebot.Frame_TGroupMessageEvent,
+			Data: &onebot.Frame_GroupMessageEvent{
+				GroupMessageEvent: &onebot.GroupMessageEvent{GroupId: 1, UserId: 2, RawMessage: "hello"},
+			},
+		}, false)
+		if err != nil {
+			t.Fatalf("%s: %v", encoding, err)
+		}
+
+		var event *eventbus.Event
+		select {
+		case event = <-received:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: event not published", encoding)
+		}
+		if event.Subject != "pbbot.123.event.TGroupMessageEvent" {
+			t.Errorf("%s: subject %s", encoding, event.Subject)
+		}
+		frame, err := eventbus.Decode(event)
+		if err != nil {
+			t.Fatalf("%s: %v", encoding, err)
+		}
+		if frame.GetGroupMessageEvent().GetRawMessage() != "hello" || frame.GetGroupMessageEvent().GetGroupId() != 1 {
+			t.Errorf("%s: decoded frame %+v", encoding, frame)
+		}
+		if len(sink.Events()) != 1 {
+			t.Errorf("%s: %d events retained", encoding, len(sink.Events()))
+		}
+		_ = publisher.Close()
+	}
+}
+
+func TestEventBusClose(t *testing.T) {
+	sink := eventbus.NewMemorySink(100)
+	release := make(chan struct{})
+	sink.Subscribe(func(event *eventbus.Event) {
+		<-release
+	})
+	publisher := eventbus.NewPublisher(sink, &eventbus.Options{QueueSize: 10})
+	bot := &pbbot.Bot{BotId: 123, Logger: pbbot.DefaultLogger}
+	frame := &onebot.Frame{BotId: 123, FrameType: onebot.Frame_TPrivateMessageEvent}
+	for i := 0; i < 5; i++ {
+		if err := publisher.Publish(bot, frame, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- publisher.Close()
+	}()
+	// Sink 阻塞时 Close 需要等待队列发布完成
+	select {
+	case err := <-closed:
+		t.Fatalf("close returned before queue drained: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("close did not return")
+	}
+	if n := len(sink.Events()); n != 5 {
+		t.Errorf("%d events published before close, want 5", n)
+	}
+
+	if err := publisher.Publish(bot, frame, false); !errors.Is(err, eventbus.ErrPublisherClosed) {
+		t.Errorf("publish after close: %v", err)
+	}
+	if err := publisher.Close(); !errors.Is(err, eventbus.ErrPublisherClosed) {
+		t.Errorf("second close: %v", err)
+	}
+	if n := len(sink.Events()); n != 5 {
+		t.Errorf("%d events after publishing to closed publisher", n)
+	}
+}
@@ -0,0 +1,185 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/websocket"
+)
+
+// fakeClient 模拟 OneBot 客户端，收到API请求后用 respond 的返回值响应，返回 nil 时不响应
+type fakeClient struct {
+	t       *testing.T
+	conn    *websocket.Conn
+	server  *httptest.Server
+	bot     *pbbot.Bot
+	respond func(req *onebot.Frame) *onebot.Frame
+
+	mu       sync.Mutex
+	requests []*onebot.Frame
+	closed   bool
+}
+
+func newFakeBot(t *testing.T, botId int64, respond func(req *onebot.Frame) *onebot.Frame) *fakeClient {
+	t.Helper()
+	return newFakeBotWithLogger(t, botId, nil, respond)
+}
+
+// newFakeBotWithLogger 机器人使用 logger 记录日志，logger 为 nil 时使用 DefaultLogger
+func newFakeBotWithLogger(t *testing.T, botId int64, logger pbbot.Logger, respond func(req *onebot.Frame) *onebot.Frame) *fakeClient {
+	t.Helper()
+	if respond == nil {
+		respond = func(req *onebot.Frame) *onebot.Frame { return &onebot.Frame{} }
+	}
+	c := &fakeClient{t: t, respond: respond}
+	c.server = httptest.NewServer(&pbbot.Server{Logger: logger})
+	header := http.Header{}
+	header.Set("x-self-id", strconv.FormatInt(botId, 10))
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(c.server.URL, "http"), header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.conn = conn
+	waitFor(t, func() bool {
+		bot, ok := pbbot.GetBot(botId)
+		c.bot = bot
+		return ok
+	})
+	go c.readLoop()
+	t.Cleanup(c.close)
+	return c
+}
+
+func (c *fakeClient) readLoop() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req onebot.Frame
+		if err := proto.Unmarshal(data, &req); err != nil {
+			continue
+		}
+		c.mu.Lock()
+		c.requests = append(c.requests, &req)
+		c.mu.Unlock()
+		resp := c.respond(&req)
+		if resp == nil {
+			continue
+		}
+		if resp.FrameType == 0 {
+			resp.FrameType = req.FrameType + 100
+		}
+		resp.BotId = req.BotId
+		resp.Echo = req.Echo
+		resp.Ok = true
+		c.write(resp)
+	}
+}
+
+func (c *fakeClient) write(frame *onebot.Frame) {
+	data, err := proto.Marshal(frame)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		c.t.Error(err)
+	}
+}
+
+// push 推送事件
+func (c *fakeClient) push(frame *onebot.Frame) {
+	frame.BotId = c.bot.BotId
+	c.write(frame)
+}
+
+// Requests 收到的API请求
+func (c *fakeClient) Requests() []*onebot.Frame {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*onebot.Frame(nil), c.requests...)
+}
+
+func (c *fakeClient) close() {
+	botId := c.bot.BotId
+	c.mu.Lock()
+	c.closed = true
+	_ = c.conn.Close()
+	c.mu.Unlock()
+	c.server.Close()
+	waitFor(c.t, func() bool {
+		bot, ok := pbbot.GetBot(botId)
+		return !ok || bot != c.bot
+	})
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// recordLogger 记录日志内容，用于检查日志等级和字段
+type recordLogger struct {
+	fields  []pbbot.Field
+	mu      *sync.Mutex
+	entries *[]logEntry
+}
+
+type logEntry struct {
+	level  pbbot.Level
+	msg    string
+	fields map[string]interface{}
+}
+
+func newRecordLogger() *recordLogger {
+	return &recordLogger{mu: &sync.Mutex{}, entries: &[]logEntry{}}
+}
+
+func (l *recordLogger) Enabled(level pbbot.Level) bool {
+	return true
+}
+
+func (l *recordLogger) Log(level pbbot.Level, msg string, fields ...pbbot.Field) {
+	entry := logEntry{level: level, msg: msg, fields: make(map[string]interface{})}
+	for _, field := range append(append([]pbbot.Field(nil), l.fields...), fields...) {
+		entry.fields[field.Key] = field.Value
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.entries = append(*l.entries, entry)
+}
+
+func (l *recordLogger) With(fields ...pbbot.Field) pbbot.Logger {
+	return &recordLogger{fields: append(append([]pbbot.Field(nil), l.fields...), fields...), mu: l.mu, entries: l.entries}
+}
+
+// Find 查找第一条 msg 相同的日志
+func (l *recordLogger) Find(msg string) (logEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, entry := range *l.entries {
+		if entry.msg == msg {
+			return entry, true
+		}
+	}
+	return logEntry{}, false
+}
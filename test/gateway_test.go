@@ -0,0 +1,141 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/gateway"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+func TestGateway(t *testing.T) {
+	g := gateway.New("secret")
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{http.MethodPost, "/bots/123/send_group_msg", "", http.StatusUnauthorized},
+		{http.MethodPost, "/bots/123/send_group_msg", "wrong", http.StatusUnauthorized},
+		{http.MethodPost, "/bots/123/unknown", "secret", http.StatusNotFound},
+		{http.MethodGet, "/bots/123/send_group_msg", "secret", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/bots/abc/send_group_msg", "secret", http.StatusBadRequest},
+		{http.MethodPost, "/bots/123/send_group_msg", "secret", http.StatusServiceUnavailable},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(`{"group_id":1,"message":[{"type":"text","data":{"text":"hi"}}]}`))
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		if w.Code != c.status {
+			t.Errorf("%s %s: status %d, want %d, body %s", c.method, c.path, w.Code, c.status, w.Body.String())
+		}
+	}
+}
+
+func TestGatewayDisconnect(t *testing.T) {
+	const botId = 48002
+	requested := make(chan struct{}, 1)
+	client := newFakeBot(t, botId, func(req *onebot.Frame) *onebot.Frame {
+		if req.FrameType == onebot.Frame_TGetStrangerInfoReq {
+			requested <- struct{}{}
+			return nil
+		}
+		return &onebot.Frame{}
+	})
+	go func() {
+		<-requested
+		_ = client.bot.Disconnect()
+	}()
+
+	// 调用过程中机器人断开时返回 bot_offline，不等待 ApiTimeout
+	start := time.Now()
+	req := httptest.NewRequest(http.MethodPost, "/bots/48002/get_stranger_info", strings.NewReader(`{"user_id":1}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	gateway.New("secret").ServeHTTP(w, req)
+	var resp gateway.ErrorResp
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusServiceUnavailable || resp.Code != gateway.CodeBotOffline {
+		t.Errorf("status %d, body %s", w.Code, w.Body.String())
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("disconnect detected after %s", time.Since(start))
+	}
+}
+
+func TestGatewayBot(t *testing.T) {
+	const botId = 48001
+	client := newFakeBot(t, botId, func(req *onebot.Frame) *onebot.Frame {
+		if r := req.GetGetStrangerInfoReq(); r != nil {
+			if r.UserId == 2 {
+				return nil
+			}
+			return &onebot.Frame{Data: &onebot.Frame_GetStrangerInfoResp{GetStrangerInfoResp: &onebot.GetStrangerInfoResp{
+				UserId: r.UserId, Nickname: "echo",
+			}}}
+		}
+		return &onebot.Frame{}
+	})
+	apiTimeout := pbbot.ApiTimeout
+	pbbot.ApiTimeout = 100 * time.Millisecond
+	defer func() { pbbot.ApiTimeout = apiTimeout }()
+	maxBodySize := gateway.MaxBodySize
+	gateway.MaxBodySize = 64
+	defer func() { gateway.MaxBodySize = maxBodySize }()
+
+	g := gateway.New("secret")
+	post := func(action string, body io.Reader) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/bots/48001/"+action, body)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		return w
+	}
+	expectError := func(w *httptest.ResponseRecorder, status int, code string) {
+		t.Helper()
+		var resp gateway.ErrorResp
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != status || resp.Code != code {
+			t.Errorf("status %d, body %s, want %d %s", w.Code, w.Body.String(), status, code)
+		}
+	}
+
+	w := post("get_stranger_info", strings.NewReader(`{"user_id":1}`))
+	var info onebot.GetStrangerInfoResp
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || info.UserId != 1 || info.Nickname != "echo" {
+		t.Errorf("status %d, body %s", w.Code, w.Body.String())
+	}
+	var sent *onebot.GetStrangerInfoReq
+	for _, req := range client.Requests() {
+		if r := req.GetGetStrangerInfoReq(); r != nil {
+			sent = r
+		}
+	}
+	if sent == nil || sent.UserId != 1 {
+		t.Errorf("request not forwarded to bot: %+v", sent)
+	}
+
+	expectError(post("get_stranger_info", strings.NewReader(`{"user_id":`)), http.StatusBadRequest, gateway.CodeBadRequest)
+	expectError(post("get_stranger_info", strings.NewReader(`{"user_id":2}`)), http.StatusGatewayTimeout, gateway.CodeTimeout)
+	expectError(post("get_stranger_info", strings.NewReader(`{"user_id":1,"no_cache":true}`+strings.Repeat(" ", 64))), http.StatusRequestEntityTooLarge, gateway.CodeBadRequest)
+	expectError(post("get_stranger_info", iotest.ErrReader(errors.New("read failed"))), http.StatusBadRequest, gateway.CodeBadRequest)
+}
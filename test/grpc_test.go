@@ -0,0 +1,124 @@
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/grpcserver"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGrpcServer(t *testing.T) {
+	data, err := proto.Marshal(&grpcserver.SubscribeEventsReq{BotIds: []int64{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subscribeReq grpcserver.SubscribeEventsReq
+	if err := proto.Unmarshal(data, &subscribeReq); err != nil || len(subscribeReq.BotIds) != 2 || subscribeReq.BotIds[1] != 2 {
+		t.Fatalf("subscribe req round trip: %v %v", subscribeReq.BotIds, err)
+	}
+
+	conn := dialGrpcServer(t, grpcserver.New())
+
+	req := &onebot.SendGroupMsgReq{GroupId: 1, Message: []*onebot.Message{{Type: "text", Data: map[string]string{"text": "hi"}}}}
+	err = conn.Invoke(context.Background(), "/onebot.OneBot/SendGroupMsg", req, &onebot.SendGroupMsgResp{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing self id: %v", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), grpcserver.SelfIdKey, "123")
+	err = conn.Invoke(ctx, "/onebot.OneBot/SendGroupMsg", req, &onebot.SendGroupMsgResp{})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("offline bot: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := conn.NewStream(ctx, &grpcserver.OneBot_ServiceDesc.Streams[0], "/onebot.OneBot/SubscribeEvents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(&grpcserver.SubscribeEventsReq{BotIds: []int64{123}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGrpcServerSubscribe(t *testing.T) {
+	const botId, otherBotId = 49001, 49002
+	client := newFakeBot(t, botId, nil)
+	other := newFakeBot(t, otherBotId, nil)
+	server := grpcserver.New()
+	server.Install()
+	oneBot := grpcserver.NewOneBotClient(dialGrpcServer(t, server))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := oneBot.SubscribeEvents(ctx, &grpcserver.SubscribeEventsReq{BotIds: []int64{botId}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 订阅在服务端收到请求后才生效，持续推送直到收到事件
+	received := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			other.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{
+				GroupMessageEvent: groupMessage(100, 1, pbbot.RoleMember, "other"),
+			}})
+			client.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{
+				GroupMessageEvent: groupMessage(100, 1, pbbot.RoleMember, "hello"),
+			}})
+			select {
+			case <-received:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	frame, err := stream.Recv()
+	close(received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frame.BotId != botId || frame.FrameType != onebot.Frame_TGroupMessageEvent {
+		t.Fatalf("unexpected frame: bot %d %s", frame.BotId, frame.FrameType)
+	}
+	if text := pbbot.ParseMsg(frame.GetGroupMessageEvent().Message).PlainText(); text != "hello" {
+		t.Errorf("event text: %q", text)
+	}
+}
+
+// dialGrpcServer 在内存连接上启动 gRPC 服务器，测试结束时关闭
+func dialGrpcServer(t *testing.T, s *grpcserver.Server) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	s.Register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+	})
+	return conn
+}
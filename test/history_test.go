@@ -0,0 +1,113 @@
+package test
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+)
+
+func saveHistory(t *testing.T, store pbbot.HistoryStore, messageId int32, target pbbot.Target, text string) {
+	t.Helper()
+	if err := store.Save(&pbbot.HistoryRecord{
+		BotId:     1,
+		MessageId: messageId,
+		Target:    target,
+		SenderId:  target.UserId,
+		Message:   pbbot.NewMsg().Text(text).MessageList,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkHistory(t *testing.T, name string, store pbbot.HistoryStore) {
+	t.Helper()
+	record, ok := store.Get(1, 2)
+	if !ok || !record.Recalled || record.OperatorId != 99 || pbbot.ParseMsg(record.Message).PlainText() != "b" {
+		t.Errorf("%s: unexpected recalled record: %+v", name, record)
+	}
+	if record, ok := store.Get(1, 1); !ok || record.Recalled {
+		t.Errorf("%s: unexpected record: %+v", name, record)
+	}
+	if records := store.ListByChat(1, pbbot.Group(100), 0); len(records) != 2 || records[0].MessageId != 3 {
+		t.Errorf("%s: unexpected chat records: %d", name, len(records))
+	}
+	if records := store.ListByUser(1, 200, 0); len(records) != 1 || records[0].MessageId != 2 {
+		t.Errorf("%s: unexpected user records: %d", name, len(records))
+	}
+}
+
+func TestHistoryStore(t *testing.T) {
+	fill := func(store pbbot.HistoryStore) {
+		saveHistory(t, store, 1, pbbot.Group(100), "a")
+		saveHistory(t, store, 2, pbbot.User(200), "b")
+		saveHistory(t, store, 3, pbbot.Group(100), "c")
+		if err := store.MarkRecalled(1, 2, 99); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	memory := pbbot.NewMemoryHistoryStore(3)
+	fill(memory)
+	checkHistory(t, "memory", memory)
+	// 超过容量时覆盖最旧的记录
+	saveHistory(t, memory, 4, pbbot.Group(100), "d")
+	if _, ok := memory.Get(1, 1); ok {
+		t.Error("oldest record should be evicted")
+	}
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store, err := pbbot.NewFileHistoryStore(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(store)
+	checkHistory(t, "file", store)
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = pbbot.NewFileHistoryStore(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHistory(t, "reopened file", store)
+
+	// 多次压缩后文件中不应有重复记录
+	for i := int32(10); i < 30; i++ {
+		saveHistory(t, store, i, pbbot.Group(100), "x")
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := countLines(t, path); lines > 6 {
+		t.Errorf("file should be compacted, lines: %d", lines)
+	}
+	store, err = pbbot.NewFileHistoryStore(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if records := store.ListByChat(1, pbbot.Group(100), 0); len(records) != 3 || records[0].MessageId != 29 {
+		t.Errorf("unexpected records after compaction: %d", len(records))
+	}
+
+	if _, err := pbbot.NewFileHistoryStore(filepath.Join(t.TempDir(), "zero.jsonl"), 0); err != pbbot.ErrInvalidHistoryCapacity {
+		t.Errorf("zero capacity should be rejected, got %v", err)
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines
+}
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+type traceKey struct{}
+
+// callTrace 记录拦截器的执行顺序
+type callTrace struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *callTrace) add(call string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+func (c *callTrace) take() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	calls := c.calls
+	c.calls = nil
+	return calls
+}
+
+func TestFrameInterceptor(t *testing.T) {
+	const botId = 43001
+	const droppedGroup = 4399
+	trace := &callTrace{}
+	done := make(chan struct{}, 10)
+	frameInterceptor := func(name string) pbbot.FrameInterceptor {
+		return func(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+			if bot.BotId != botId || frame.GetGroupMessageEvent() == nil {
+				next(ctx)
+				return
+			}
+			trace.add(name + " before")
+			next(context.WithValue(ctx, traceKey{}, name))
+			trace.add(name + " after")
+		}
+	}
+	pbbot.UseFrameInterceptor(
+		func(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+			next(ctx)
+			if bot.BotId == botId && frame.GetGroupMessageEvent() != nil {
+				done <- struct{}{}
+			}
+		},
+		frameInterceptor("a"),
+		frameInterceptor("b"),
+		func(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+			if bot.BotId == botId && frame.GetGroupMessageEvent().GetGroupId() == droppedGroup {
+				trace.add("drop")
+				return
+			}
+			next(ctx)
+		},
+	)
+	pbbot.UseMessageHandler(func(ctx *pbbot.MessageContext) {
+		if ctx.Bot.BotId == botId {
+			// 处理函数收到内层拦截器设置的 ctx
+			trace.add("handler " + ctx.Bot.Context().Value(traceKey{}).(string))
+		}
+	})
+
+	client := newFakeBot(t, botId, nil)
+	push := func(groupId int64) []string {
+		client.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{
+			GroupMessageEvent: groupMessage(groupId, 1, pbbot.RoleMember, "hi"),
+		}})
+		<-done
+		return trace.take()
+	}
+
+	if calls, want := push(4301), []string{"a before", "b before", "handler b", "b after", "a after"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got %q, want %q", calls, want)
+	}
+	if calls, want := push(droppedGroup), []string{"a before", "b before", "drop", "b after", "a after"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got %q, want %q", calls, want)
+	}
+}
+
+func TestApiInterceptor(t *testing.T) {
+	const botId = 43002
+	trace := &callTrace{}
+	apiInterceptor := func(name string) pbbot.ApiInterceptor {
+		return func(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+			if bot.BotId != botId || req.GetGetLoginInfoReq() == nil {
+				return next(ctx)
+			}
+			if req.Echo == "" || req.BotId != botId {
+				t.Errorf("request should have echo and bot id: %+v", req)
+			}
+			trace.add(name + " before")
+			resp, err := next(ctx)
+			trace.add(name + " after")
+			return resp, err
+		}
+	}
+	var shortCircuit bool
+	pbbot.UseApiInterceptor(
+		apiInterceptor("a"),
+		apiInterceptor("b"),
+		func(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+			if bot.BotId != botId || req.GetGetLoginInfoReq() == nil || !shortCircuit {
+				return next(ctx)
+			}
+			trace.add("cached")
+			return &onebot.Frame{Data: &onebot.Frame_GetLoginInfoResp{GetLoginInfoResp: &onebot.GetLoginInfoResp{Nickname: "cached"}}}, nil
+		},
+	)
+	client := newFakeBot(t, botId, func(req *onebot.Frame) *onebot.Frame {
+		if req.FrameType == onebot.Frame_TGetLoginInfoReq {
+			trace.add("client")
+			return &onebot.Frame{Data: &onebot.Frame_GetLoginInfoResp{GetLoginInfoResp: &onebot.GetLoginInfoResp{Nickname: "remote"}}}
+		}
+		return &onebot.Frame{}
+	})
+
+	resp, err := client.bot.GetLoginInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls, want := trace.take(), []string{"a before", "b before", "client", "b after", "a after"}; !reflect.DeepEqual(calls, want) || resp.Nickname != "remote" {
+		t.Errorf("got %q, want %q, nickname %s", calls, want, resp.Nickname)
+	}
+
+	// 拦截器不调用 next 时直接返回，不发送请求
+	shortCircuit = true
+	resp, err = client.bot.GetLoginInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls, want := trace.take(), []string{"a before", "b before", "cached", "b after", "a after"}; !reflect.DeepEqual(calls, want) || resp.Nickname != "cached" {
+		t.Errorf("got %q, want %q, nickname %s", calls, want, resp.Nickname)
+	}
+	if n := len(sendRequestsOf(client.Requests(), onebot.Frame_TGetLoginInfoReq)); n != 1 {
+		t.Errorf("short-circuited call should not be sent, requests: %d", n)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build go1.21
+// +build go1.21
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: pbbot.SlogLevelTrace}))
+	logger := pbbot.NewSlogLogger(base).With(pbbot.F(pbbot.FieldBotId, int64(1)))
+	if !logger.Enabled(pbbot.LevelTrace) {
+		t.Error("trace should be enabled")
+	}
+	logger.Log(pbbot.LevelWarn, "warn", pbbot.F(pbbot.FieldGroupId, int64(2)), pbbot.Err(errors.New("boom")))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["level"] != "WARN" || entry["msg"] != "warn" || entry[pbbot.FieldBotId] != float64(1) ||
+		entry[pbbot.FieldGroupId] != float64(2) || entry[pbbot.FieldError] != "boom" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/zaplog"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogrusLogger(t *testing.T) {
+	base, hook := logrustest.NewNullLogger()
+	base.SetLevel(logrus.InfoLevel)
+	logger := pbbot.NewLogrusLogger(base).With(pbbot.F(pbbot.FieldBotId, int64(1)))
+	if logger.Enabled(pbbot.LevelDebug) || !logger.Enabled(pbbot.LevelWarn) {
+		t.Error("unexpected enabled levels")
+	}
+
+	logger.Log(pbbot.LevelWarn, "warn", pbbot.F(pbbot.FieldGroupId, int64(2)), pbbot.Err(errors.New("boom")))
+	logger.With(pbbot.F(pbbot.FieldUserId, int64(3))).Log(pbbot.LevelError, "error")
+	logger.Log(pbbot.LevelDebug, "debug")
+
+	entries := hook.AllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	warn := entries[0]
+	if warn.Level != logrus.WarnLevel || warn.Message != "warn" || warn.Data[pbbot.FieldBotId] != int64(1) ||
+		warn.Data[pbbot.FieldGroupId] != int64(2) || warn.Data[pbbot.FieldError].(error).Error() != "boom" {
+		t.Errorf("unexpected entry: %+v", warn)
+	}
+	if entries[1].Data[pbbot.FieldBotId] != int64(1) || entries[1].Data[pbbot.FieldUserId] != int64(3) {
+		t.Errorf("fields should be inherited: %+v", entries[1].Data)
+	}
+	if _, ok := warn.Data[pbbot.FieldUserId]; ok {
+		t.Error("With should not modify parent logger")
+	}
+}
+
+func TestZapLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zaplog.New(zap.New(core)).With(pbbot.F(pbbot.FieldBotId, int64(1)))
+	if !logger.Enabled(pbbot.LevelTrace) {
+		t.Error("trace should be logged as debug")
+	}
+
+	logger.Log(pbbot.LevelTrace, "trace", pbbot.F(pbbot.FieldEcho, "e1"))
+	logger.With(pbbot.F(pbbot.FieldUserId, int64(3))).Log(pbbot.LevelError, "error", pbbot.Err(errors.New("boom")))
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	trace := entries[0].ContextMap()
+	if entries[0].Level != zapcore.DebugLevel || trace[pbbot.FieldBotId] != int64(1) || trace[pbbot.FieldEcho] != "e1" {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+	fields := entries[1].ContextMap()
+	if entries[1].Level != zapcore.ErrorLevel || fields[pbbot.FieldBotId] != int64(1) || fields[pbbot.FieldUserId] != int64(3) || fields[pbbot.FieldError] != "boom" {
+		t.Errorf("unexpected fields: %+v", fields)
+	}
+}
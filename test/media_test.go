@@ -0,0 +1,103 @@
+package test
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+)
+
+func TestMediaEncoding(t *testing.T) {
+	data := []byte("\x89PNG\r\n\x1a\nfake image")
+	msg := pbbot.NewMsg().ImageBytes(data, pbbot.WithImageType(pbbot.ImageTypeFlash))
+	if err := msg.Err(); err != nil {
+		t.Fatal(err)
+	}
+	image := msg.MessageList[0]
+	if image.Data["file"] != "base64://"+base64.StdEncoding.EncodeToString(data) || image.Data["type"] != pbbot.ImageTypeFlash {
+		t.Errorf("unexpected base64 image: %+v", image.Data)
+	}
+	if _, ok := image.Data["url"]; ok {
+		t.Error("encoded image should only set file")
+	}
+
+	path := filepath.Join(t.TempDir(), "a.amr")
+	if err := ioutil.WriteFile(path, []byte("record"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	msg = pbbot.NewMsg().RecordFile(path)
+	if err := msg.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if file := msg.MessageList[0].Data["file"]; file != "file://"+filepath.ToSlash(path) {
+		t.Errorf("unexpected record file: %s", file)
+	}
+
+	if err := pbbot.NewMsg().ImageFile(filepath.Join(t.TempDir(), "missing.png")).Err(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	if err := pbbot.NewMsg().ImageBytes(nil).Err(); err == nil {
+		t.Errorf("expected error for empty image")
+	}
+	maxImageSize := pbbot.MaxImageSize
+	pbbot.MaxImageSize = 4
+	if err := pbbot.NewMsg().ImageBytes(data).Err(); err == nil {
+		t.Errorf("expected error for oversized image")
+	}
+	pbbot.MaxImageSize = maxImageSize
+}
+
+func TestMediaServer(t *testing.T) {
+	mediaServer := pbbot.NewMediaServer("http://127.0.0.1/media/", time.Minute)
+	pbbot.DefaultMediaServer = mediaServer
+	defer func() {
+		pbbot.DefaultMediaServer = nil
+	}()
+
+	data := []byte("\x89PNG\r\n\x1a\nfake image")
+	msg := pbbot.NewMsg().ImageBytes(data)
+	if err := msg.Err(); err != nil {
+		t.Fatal(err)
+	}
+	url := msg.MessageList[0].Data["file"]
+	if !strings.HasPrefix(url, "http://127.0.0.1/media/") {
+		t.Fatalf("unexpected media url: %s", url)
+	}
+
+	w := httptest.NewRecorder()
+	mediaServer.ServeHTTP(w, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(url, "http://127.0.0.1"), nil))
+	if w.Code != http.StatusOK || w.Body.String() != string(data) || w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("unexpected media response: %d %s %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mediaServer.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/media/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown media, got %d", w.Code)
+	}
+
+	expired := pbbot.NewMediaServer("http://127.0.0.1/media", -time.Second)
+	url = expired.Put(data)
+	w = httptest.NewRecorder()
+	expired.ServeHTTP(w, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(url, "http://127.0.0.1"), nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for expired media, got %d", w.Code)
+	}
+
+	// 没有新的请求时，过期文件也会从内存中删除
+	short := pbbot.NewMediaServer("http://127.0.0.1/media", 10*time.Millisecond)
+	short.Put(data)
+	short.Put(data)
+	if short.Len() != 2 {
+		t.Fatalf("expected 2 files, got %d", short.Len())
+	}
+	waitFor(t, func() bool { return short.Len() == 0 })
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/metrics"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// metricFilter 从 collector 中选出名称和标签都匹配的指标，用于 testutil.ToFloat64
+type metricFilter struct {
+	collector prometheus.Collector
+	name      string
+	labels    map[string]string
+}
+
+func (f *metricFilter) Describe(ch chan<- *prometheus.Desc) {
+	f.collector.Describe(ch)
+}
+
+func (f *metricFilter) Collect(ch chan<- prometheus.Metric) {
+	all := make(chan prometheus.Metric)
+	go func() {
+		f.collector.Collect(all)
+		close(all)
+	}()
+	for metric := range all {
+		if !strings.Contains(metric.Desc().String(), fmt.Sprintf("fqName: %q", f.name)) {
+			continue
+		}
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			continue
+		}
+		matched := 0
+		for _, label := range m.Label {
+			if f.labels[label.GetName()] == label.GetValue() {
+				matched++
+			}
+		}
+		if matched == len(f.labels) {
+			ch <- metric
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	const botId = 45001
+	m, err := metrics.Register(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := func(name string, labels ...string) float64 {
+		filter := &metricFilter{collector: m, name: name, labels: make(map[string]string)}
+		for i := 0; i+1 < len(labels); i += 2 {
+			filter.labels[labels[i]] = labels[i+1]
+		}
+		// 标签组合第一次出现之前没有对应的指标
+		if testutil.CollectAndCount(filter) == 0 {
+			return 0
+		}
+		return testutil.ToFloat64(filter)
+	}
+	// 在指标拦截器内层返回包装过的错误
+	pbbot.UseApiInterceptor(func(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+		if bot.BotId != botId {
+			return next(ctx)
+		}
+		switch req.FrameType {
+		case onebot.Frame_TGetStrangerInfoReq:
+			return nil, fmt.Errorf("get stranger info: %w", pbbot.ErrTimeout)
+		case onebot.Frame_TGetGroupInfoReq:
+			return nil, fmt.Errorf("get group info: %w", context.Canceled)
+		}
+		return next(ctx)
+	})
+	client := newFakeBot(t, botId, nil)
+	if n := value("pbbot_connected_bots"); n != float64(len(pbbot.ListBots())) {
+		t.Errorf("unexpected connected bots: %v", n)
+	}
+
+	calls := value("pbbot_api_calls_total", "frame_type", "TGetLoginInfoReq")
+	if _, err := client.bot.GetLoginInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if n := value("pbbot_api_calls_total", "frame_type", "TGetLoginInfoReq"); n != calls+1 {
+		t.Errorf("api calls should increase, got %v", n)
+	}
+
+	timeouts := value("pbbot_api_errors_total", "frame_type", "TGetStrangerInfoReq", "reason", "timeout")
+	if _, err := client.bot.GetStrangerInfo(1, false); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := value("pbbot_api_errors_total", "frame_type", "TGetStrangerInfoReq", "reason", "timeout"); n != timeouts+1 {
+		t.Errorf("wrapped timeout should be counted as timeout, got %v", n)
+	}
+	cancelled := value("pbbot_api_errors_total", "frame_type", "TGetGroupInfoReq", "reason", "cancelled")
+	if _, err := client.bot.GetGroupInfo(1, false); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := value("pbbot_api_errors_total", "frame_type", "TGetGroupInfoReq", "reason", "cancelled"); n != cancelled+1 {
+		t.Errorf("wrapped cancel should be counted as cancelled, got %v", n)
+	}
+}
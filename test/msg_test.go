@@ -0,0 +1,164 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+func TestParseMsg(t *testing.T) {
+	messageList := []*onebot.Message{
+		{Type: "reply", Data: map[string]string{"id": "123"}},
+		{Type: "at", Data: map[string]string{"qq": "10001"}},
+		{Type: "text", Data: map[string]string{"text": "hello "}},
+		{Type: "image", Data: map[string]string{"file": "a.png", "url": "http://a/a.png", "subType": "1"}},
+		{Type: "at", Data: map[string]string{"qq": "all"}},
+		{Type: "text", Data: map[string]string{"text": "world"}},
+		{Type: "unknown_type", Data: map[string]string{"foo": "bar"}},
+	}
+	msg := pbbot.ParseMsg(messageList)
+
+	if text := msg.PlainText(); text != "hello world" {
+		t.Errorf("unexpected plain text: %s", text)
+	}
+	if mentions := msg.Mentions(); len(mentions) != 1 || mentions[0] != 10001 {
+		t.Errorf("unexpected mentions: %+v", mentions)
+	}
+	if !msg.MentionsMe(10001) || msg.MentionsMe(10002) {
+		t.Errorf("unexpected MentionsMe result")
+	}
+	if !msg.MentionsAll() {
+		t.Errorf("expected MentionsAll")
+	}
+	if images := msg.Images(); len(images) != 1 || images[0].Url != "http://a/a.png" {
+		t.Errorf("unexpected images: %+v", images)
+	}
+	if reply := msg.GetReply(); reply == nil || reply.Id != 123 {
+		t.Errorf("unexpected reply: %+v", reply)
+	}
+
+	roundTrip := pbbot.NewMsg().Append(msg.Segments()...)
+	last := roundTrip.MessageList[len(roundTrip.MessageList)-1]
+	if last.Type != "unknown_type" || last.Data["foo"] != "bar" {
+		t.Errorf("unknown segment not preserved: %+v", last)
+	}
+	image := roundTrip.MessageList[3]
+	if image.Data["subType"] != "1" || image.Data["file"] != "a.png" {
+		t.Errorf("extra image data not preserved: %+v", image)
+	}
+}
+
+func TestMsgSplit(t *testing.T) {
+	msg := pbbot.NewMsg().At(10001).Text("你好世界\nhello").Image("http://a/a.png").Text("abc")
+	parts := msg.Split(4, 0)
+
+	var text string
+	segments := 0
+	for _, part := range parts {
+		partChars := 0
+		for _, message := range part.MessageList {
+			if message.Type == "text" {
+				partChars += len([]rune(message.Data["text"]))
+			}
+		}
+		if partChars > 4 {
+			t.Errorf("part exceeds budget: %+v", part.MessageList)
+		}
+		text += part.PlainText()
+		segments += len(part.Images()) + len(part.Mentions())
+	}
+	if text != "你好世界\nhelloabc" {
+		t.Errorf("text changed after split: %q", text)
+	}
+	if segments != 2 {
+		t.Errorf("at/image segments lost: %d", segments)
+	}
+
+	if parts := msg.Split(0, 2); len(parts) != 2 {
+		t.Errorf("unexpected segment split: %d", len(parts))
+	}
+}
+
+func TestMsgSplitBoundaries(t *testing.T) {
+	partTexts := func(parts []*pbbot.Msg) [][]string {
+		result := make([][]string, 0, len(parts))
+		for _, part := range parts {
+			texts := make([]string, 0, len(part.MessageList))
+			for _, message := range part.MessageList {
+				if message.Type == "text" {
+					texts = append(texts, message.Data["text"])
+				} else {
+					texts = append(texts, "["+message.Type+"]")
+				}
+			}
+			result = append(result, texts)
+		}
+		return result
+	}
+	cases := []struct {
+		name        string
+		msg         *pbbot.Msg
+		maxChars    int
+		maxSegments int
+		want        [][]string
+	}{
+		{
+			name:     "mixed text and image",
+			msg:      pbbot.NewMsg().Text("ab").Image("http://a/a.png").Text("cdef"),
+			maxChars: 3,
+			want:     [][]string{{"ab", "[image]", "c"}, {"def"}},
+		},
+		{
+			name:     "segment cannot be split",
+			msg:      pbbot.NewMsg().Json(`{"app":"a very long card that is not text"}`).Text("x"),
+			maxChars: 1,
+			want:     [][]string{{"[json]", "x"}},
+		},
+		{
+			name:        "one segment per part",
+			msg:         pbbot.NewMsg().Text("a").Image("http://a/a.png").At(1),
+			maxSegments: 1,
+			want:        [][]string{{"a"}, {"[image]"}, {"[at]"}},
+		},
+		{
+			name:     "multi-byte characters",
+			msg:      pbbot.NewMsg().Text("你好世界"),
+			maxChars: 3,
+			want:     [][]string{{"你好世"}, {"界"}},
+		},
+		{
+			name:     "prefer newline",
+			msg:      pbbot.NewMsg().Text("ab\ncdef"),
+			maxChars: 5,
+			want:     [][]string{{"ab\n"}, {"cdef"}},
+		},
+	}
+	for _, c := range cases {
+		got := partTexts(c.msg.Split(c.maxChars, c.maxSegments))
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestForwardMsg(t *testing.T) {
+	inner := pbbot.NewForwardMsg().CustomNode("inner", 2, pbbot.NewMsg().Text("deep"))
+	forwardMsg := pbbot.NewForwardMsg().
+		Node(100).
+		CustomNode("bot", 1, pbbot.NewMsg().Text("hi").At(3)).
+		CustomNode("nested", 1, inner.Msg())
+
+	parsed := pbbot.ParseForwardMsg(forwardMsg.Msg().MessageList)
+	if len(parsed.Nodes) != 3 || parsed.Nodes[0].Id != 100 {
+		t.Fatalf("unexpected nodes: %+v", parsed.Nodes)
+	}
+	if parsed.Nodes[1].Name != "bot" || parsed.Nodes[1].Uin != 1 || len(parsed.Nodes[1].Content) != 2 {
+		t.Errorf("unexpected custom node: %+v", parsed.Nodes[1])
+	}
+	nested, ok := parsed.Nodes[2].Content[0].(*pbbot.NodeSegment)
+	if !ok || nested.Name != "inner" || nested.Content[0].(*pbbot.TextSegment).Text != "deep" {
+		t.Errorf("unexpected nested node: %+v", parsed.Nodes[2].Content)
+	}
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/plugin"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/ProtobufBot/go-pbbot/storage"
+)
+
+type testPlugin struct {
+	name    string
+	init    func(r *plugin.Registrar)
+	initErr error
+}
+
+func (p *testPlugin) Name() string { return p.name }
+
+func (p *testPlugin) Init(ctx context.Context, r *plugin.Registrar) error {
+	p.init(r)
+	return p.initErr
+}
+
+func (p *testPlugin) Shutdown(ctx context.Context) error { return nil }
+
+func TestPluginDroppedMessage(t *testing.T) {
+	const botId = 42001
+	const droppedGroup = 4299
+
+	manager := plugin.NewManager(storage.NewMemoryStore(), nil)
+	manager.Install()
+	defer manager.Shutdown(context.Background())
+	received := make(chan int64, 10)
+	if err := manager.Register(context.Background(), &testPlugin{name: "record", init: func(r *plugin.Registrar) {
+		r.HandleMessage(func(ctx *pbbot.MessageContext) {
+			if ctx.Bot.BotId == botId {
+				received <- ctx.GroupId
+			}
+		})
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	// Install 之后添加的中间件丢弃消息时，插件不应收到消息
+	pbbot.UseMessageMiddleware(func(ctx *pbbot.MessageContext, next func()) {
+		if ctx.Bot.BotId == botId && ctx.GroupId == droppedGroup {
+			return
+		}
+		next()
+	})
+	handled := make(chan int64, 10)
+	pbbot.UseFrameInterceptor(func(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+		next(ctx)
+		if bot.BotId == botId && frame.GetGroupMessageEvent() != nil {
+			handled <- frame.GetGroupMessageEvent().GroupId
+		}
+	})
+
+	client := newFakeBot(t, botId, nil)
+	pushGroup := func(groupId int64) {
+		client.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{
+			GroupMessageEvent: groupMessage(groupId, 1, pbbot.RoleMember, "hi"),
+		}})
+		<-handled
+	}
+
+	pushGroup(droppedGroup)
+	select {
+	case groupId := <-received:
+		t.Fatalf("plugin should not receive dropped message, group %d", groupId)
+	default:
+	}
+	pushGroup(4201)
+	select {
+	case groupId := <-received:
+		if groupId != 4201 {
+			t.Errorf("unexpected group %d", groupId)
+		}
+	default:
+		t.Error("plugin should receive message")
+	}
+}
+
+func TestPluginIsolation(t *testing.T) {
+	const botId = 42002
+	manager := plugin.NewManager(storage.NewMemoryStore(), nil)
+	manager.Install()
+	defer manager.Shutdown(context.Background())
+	received := make(chan int64, 10)
+	register := func(name string, fn func(ctx *pbbot.MessageContext)) {
+		if err := manager.Register(context.Background(), &testPlugin{name: name, init: func(r *plugin.Registrar) {
+			r.HandleMessage(func(ctx *pbbot.MessageContext) {
+				if ctx.Bot.BotId == botId {
+					fn(ctx)
+				}
+			})
+		}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	register("boom42", func(ctx *pbbot.MessageContext) { panic("boom") })
+	register("record42", func(ctx *pbbot.MessageContext) { received <- ctx.GroupId })
+	handled := make(chan struct{}, 10)
+	pbbot.UseFrameInterceptor(func(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+		next(ctx)
+		if bot.BotId == botId && frame.GetGroupMessageEvent() != nil {
+			handled <- struct{}{}
+		}
+	})
+	client := newFakeBot(t, botId, nil)
+	pushGroup := func(groupId int64) {
+		client.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{
+			GroupMessageEvent: groupMessage(groupId, 1, pbbot.RoleMember, "hi"),
+		}})
+		<-handled
+	}
+	panics := func() map[string]uint64 {
+		result := make(map[string]uint64)
+		for _, info := range manager.Plugins() {
+			result[info.Name] = info.Panics
+		}
+		return result
+	}
+
+	// panic 记录到对应插件，不影响其他插件
+	pushGroup(4201)
+	if groupId := <-received; groupId != 4201 {
+		t.Errorf("unexpected group %d", groupId)
+	}
+	if p := panics(); p["boom42"] != 1 || p["record42"] != 0 {
+		t.Errorf("unexpected panics: %v", p)
+	}
+
+	// 在群中禁用的插件不会收到消息
+	if err := manager.Disable("record42", 4202); err != nil {
+		t.Fatal(err)
+	}
+	pushGroup(4202)
+	select {
+	case groupId := <-received:
+		t.Errorf("disabled plugin received message in group %d", groupId)
+	default:
+	}
+	if p := panics(); p["boom42"] != 2 {
+		t.Errorf("enabled plugin should still run in group 4202: %v", p)
+	}
+	pushGroup(4201)
+	if groupId := <-received; groupId != 4201 {
+		t.Errorf("unexpected group %d", groupId)
+	}
+
+	// 初始化失败或 panic 时撤销注册
+	commandCalls := make(chan string, 10)
+	command := func(r *plugin.Registrar) {
+		r.Command(&pbbot.Command{Name: r.Name(), Handler: func(ctx *pbbot.MessageContext, args []string) { commandCalls <- r.Name() }})
+	}
+	for _, p := range []*testPlugin{
+		{name: "panic42", init: func(r *plugin.Registrar) {
+			command(r)
+			panic("init")
+		}},
+		{name: "fail42", init: command, initErr: errors.New("init failed")},
+	} {
+		if err := manager.Register(context.Background(), p); err == nil {
+			t.Errorf("%s: expected init error", p.name)
+		}
+		if _, ok := panics()[p.name]; ok {
+			t.Errorf("%s: plugin should be removed after init failure", p.name)
+		}
+		client.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{
+			GroupMessageEvent: groupMessage(4201, 1, pbbot.RoleMember, "/"+p.name),
+		}})
+		<-handled
+		<-received
+		select {
+		case name := <-commandCalls:
+			t.Errorf("command %s should be unregistered", name)
+		default:
+		}
+	}
+	if names := manager.Features(); len(names) != 2 || names[0] != "boom42" || names[1] != "record42" {
+		t.Errorf("unexpected plugins after failed init: %v", names)
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+)
+
+func TestRateLimitRefill(t *testing.T) {
+	limiter := pbbot.NewRateLimiter(pbbot.RateLimitConfig{
+		Group: pbbot.RateLimit{Rate: 20, Burst: 2},
+	})
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(ctx, 1, 0, pbbot.PriorityNormal); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("burst should not wait, elapsed %s", elapsed)
+	}
+	// 每个群单独限流
+	if err := limiter.Wait(ctx, 2, 0, pbbot.PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("other group should not wait, elapsed %s", elapsed)
+	}
+	// 令牌用完后按 Rate 生成，20/s 即 50ms 一个
+	if err := limiter.Wait(ctx, 1, 0, pbbot.PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("should wait for refill, elapsed %s", elapsed)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(timeoutCtx, 1, 0, pbbot.PriorityNormal); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	stats := limiter.Stats()
+	if stats.Allowed != 4 || stats.Throttled < 1 || stats.Cancelled != 1 || stats.Waiting != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestRateLimitPriority(t *testing.T) {
+	limiter := pbbot.NewRateLimiter(pbbot.RateLimitConfig{
+		Bot: pbbot.RateLimit{Rate: 10, Burst: 1},
+	})
+	ctx := context.Background()
+	if err := limiter.Wait(ctx, 0, 0, pbbot.PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	order := make([]pbbot.Priority, 0, 4)
+	var wg sync.WaitGroup
+	// 令牌在 100ms 后才会生成，依次加入队列的请求按优先级发送，同优先级先到先发
+	for _, priority := range []pbbot.Priority{pbbot.PriorityLow, pbbot.PriorityNormal, pbbot.PriorityHigh, pbbot.PriorityLow} {
+		wg.Add(1)
+		go func(priority pbbot.Priority) {
+			defer wg.Done()
+			if err := limiter.Wait(ctx, 0, 0, priority); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+		}(priority)
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+	want := []pbbot.Priority{pbbot.PriorityHigh, pbbot.PriorityNormal, pbbot.PriorityLow, pbbot.PriorityLow}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("unexpected order: %v", order)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/scheduler"
+	"github.com/ProtobufBot/go-pbbot/storage"
+)
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2021, 1, 30, 23, 58, 30, 0, time.UTC) // 周六
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2021, 1, 30, 23, 59, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2021, 1, 31, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1-5", time.Date(2021, 2, 1, 8, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := scheduler.ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", c.expr, err)
+		}
+		if got := schedule.Next(base); !got.Equal(c.want) {
+			t.Errorf("%q: got %v, want %v", c.expr, got, c.want)
+		}
+	}
+	for _, expr := range []string{"* * * *", "60 * * * *", "a * * * *", "*/0 * * * *"} {
+		if _, err := scheduler.ParseCron(expr); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func reminderCount(t *testing.T, store storage.Store) int {
+	t.Helper()
+	keys, err := store.Keys("scheduler/reminders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(keys)
+}
+
+func TestReminderPersist(t *testing.T) {
+	const botId = 41001
+	store := storage.NewMemoryStore()
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	id, err := scheduler.New(store).Remind(at, botId, pbbot.Group(100), pbbot.NewMsg().Text("remind"), scheduler.OfflineSkip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 重启后从存储中恢复提醒
+	s := scheduler.New(store)
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+	jobs := s.Jobs()
+	if len(jobs) != 1 || jobs[0].Id != id || jobs[0].BotId != botId || !jobs[0].Next().Equal(at) {
+		t.Fatalf("unexpected reloaded jobs: %+v", jobs)
+	}
+
+	client := newFakeBot(t, botId, messageIdResponder())
+	s.Tick(at)
+	waitFor(t, func() bool { return len(sendRequests(client.Requests())) == 1 })
+	req := sendRequests(client.Requests())[0].GetSendMsgReq()
+	if req.GroupId != 100 || pbbot.ParseMsg(req.Message).PlainText() != "remind" {
+		t.Errorf("unexpected reminder message: %+v", req)
+	}
+	waitFor(t, func() bool { return reminderCount(t, store) == 0 })
+	if len(s.Jobs()) != 0 {
+		t.Error("reminder should be removed after sending")
+	}
+}
+
+func TestSchedulerOfflineSkip(t *testing.T) {
+	const botId = 41002
+	store := storage.NewMemoryStore()
+	s := scheduler.New(store)
+	var calls int32
+	s.After(0, botId, scheduler.OfflineSkip, func(bot *pbbot.Bot) { atomic.AddInt32(&calls, 1) })
+	if _, err := s.Remind(time.Now(), botId, pbbot.User(1), pbbot.NewMsg().Text("skip"), scheduler.OfflineSkip); err != nil {
+		t.Fatal(err)
+	}
+	s.Tick(time.Now())
+
+	newFakeBot(t, botId, nil)
+	s.Tick(time.Now().Add(time.Minute))
+	if atomic.LoadInt32(&calls) != 0 || len(s.Jobs()) != 0 || reminderCount(t, store) != 0 {
+		t.Errorf("offline jobs should be skipped, calls: %d, jobs: %d", calls, len(s.Jobs()))
+	}
+}
+
+func TestSchedulerOfflineDefer(t *testing.T) {
+	const botId = 41003
+	s := scheduler.New(nil)
+	var calls int32
+	if err := s.Cron("cron", "* * * * *", botId, scheduler.OfflineDefer, func(bot *pbbot.Bot) { atomic.AddInt32(&calls, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	first := s.Jobs()[0].Next()
+	// 机器人离线期间 cron 任务触发多次，上线后只补执行一次
+	for i := 0; i < 3; i++ {
+		s.Tick(first.Add(time.Duration(i) * time.Minute))
+	}
+	newFakeBot(t, botId, nil)
+	s.Tick(first.Add(2*time.Minute + time.Second))
+	waitFor(t, func() bool { return atomic.LoadInt32(&calls) == 1 })
+	s.Tick(first.Add(2*time.Minute + 2*time.Second))
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("deferred cron job should run once, got %d", n)
+	}
+}
+
+func TestSchedulerDeferDeadline(t *testing.T) {
+	const botId = 41004
+	store := storage.NewMemoryStore()
+	s := scheduler.New(store)
+	s.MaxDefer = time.Minute
+	var calls int32
+	s.After(0, botId, scheduler.OfflineDefer, func(bot *pbbot.Bot) { atomic.AddInt32(&calls, 1) })
+	if _, err := s.Remind(time.Now(), botId, pbbot.User(1), pbbot.NewMsg().Text("late"), scheduler.OfflineDefer); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	s.Tick(now)
+	if reminderCount(t, store) != 1 {
+		t.Fatal("deferred reminder should be kept until deadline")
+	}
+	// 超过 MaxDefer 后跳过，机器人上线后也不再执行
+	s.Tick(now.Add(2 * time.Minute))
+	client := newFakeBot(t, botId, nil)
+	s.Tick(now.Add(3 * time.Minute))
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != 0 || len(sendRequests(client.Requests())) != 0 || reminderCount(t, store) != 0 {
+		t.Errorf("jobs past deadline should be skipped, calls: %d", calls)
+	}
+}
+
+func TestSchedulerCancelDeferred(t *testing.T) {
+	const botId = 41005
+	store := storage.NewMemoryStore()
+	s := scheduler.New(store)
+	var calls int32
+	if err := s.Cron("cron", "* * * * *", botId, scheduler.OfflineDefer, func(bot *pbbot.Bot) { atomic.AddInt32(&calls, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.Remind(time.Now(), botId, pbbot.User(1), pbbot.NewMsg().Text("cancelled"), scheduler.OfflineDefer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := s.Jobs()[0].Next()
+	for _, job := range s.Jobs() {
+		if job.Next().After(now) {
+			now = job.Next()
+		}
+	}
+	s.Tick(now)
+	s.Cancel(id)
+	s.Cancel("cron")
+	if n := reminderCount(t, store); n != 0 {
+		t.Errorf("cancelled deferred reminder should be deleted, %d left", n)
+	}
+
+	// 重启后不会恢复已取消的提醒
+	restarted := scheduler.New(store)
+	if err := restarted.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Stop()
+	if jobs := restarted.Jobs(); len(jobs) != 0 {
+		t.Errorf("cancelled reminder reloaded: %+v", jobs)
+	}
+
+	client := newFakeBot(t, botId, nil)
+	s.Tick(now.Add(time.Second))
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != 0 || len(sendRequests(client.Requests())) != 0 || len(s.Jobs()) != 0 {
+		t.Errorf("cancelled jobs should not run, calls: %d, jobs: %d", calls, len(s.Jobs()))
+	}
+}
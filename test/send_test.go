@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+)
+
+// messageIdResponder 为发送消息的请求依次返回递增的消息ID
+func messageIdResponder() func(req *onebot.Frame) *onebot.Frame {
+	var messageId int32
+	return func(req *onebot.Frame) *onebot.Frame {
+		switch req.FrameType {
+		case onebot.Frame_TSendGroupMsgReq:
+			return &onebot.Frame{Data: &onebot.Frame_SendGroupMsgResp{SendGroupMsgResp: &onebot.SendGroupMsgResp{MessageId: atomic.AddInt32(&messageId, 1)}}}
+		case onebot.Frame_TSendPrivateMsgReq:
+			return &onebot.Frame{Data: &onebot.Frame_SendPrivateMsgResp{SendPrivateMsgResp: &onebot.SendPrivateMsgResp{MessageId: atomic.AddInt32(&messageId, 1)}}}
+		case onebot.Frame_TSendMsgReq:
+			return &onebot.Frame{Data: &onebot.Frame_SendMsgResp{SendMsgResp: &onebot.SendMsgResp{MessageId: atomic.AddInt32(&messageId, 1)}}}
+		}
+		return &onebot.Frame{}
+	}
+}
+
+// sendRequests 只保留发送消息的请求，忽略连接时缓存加载等请求
+func sendRequests(requests []*onebot.Frame) []*onebot.Frame {
+	result := make([]*onebot.Frame, 0, len(requests))
+	for _, req := range requests {
+		switch req.FrameType {
+		case onebot.Frame_TSendGroupMsgReq, onebot.Frame_TSendPrivateMsgReq, onebot.Frame_TSendMsgReq:
+			result = append(result, req)
+		}
+	}
+	return result
+}
+
+func TestSendSplit(t *testing.T) {
+	client := newFakeBot(t, 30001, messageIdResponder())
+	bot := client.bot
+
+	var ids []int32
+	resp, err := bot.SendGroupMessage(1, pbbot.NewMsg().Text("abcdefg"), false,
+		pbbot.WithSplit(&pbbot.SplitOptions{MaxChars: 3, Delay: time.Millisecond}), pbbot.WithMessageIds(&ids))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 || resp.MessageId != 3 {
+		t.Errorf("unexpected message ids: %v, resp: %+v", ids, resp)
+	}
+	requests := sendRequests(client.Requests())
+	if len(requests) != 3 || requests[2].GetSendGroupMsgReq().Message[0].Data["text"] != "g" {
+		t.Errorf("unexpected requests: %+v", requests)
+	}
+
+	// 条数达到阈值时合并转发，节点名默认为机器人QQ号
+	_, err = bot.SendPrivateMessage(2, pbbot.NewMsg().Text("abcdefg"), false,
+		pbbot.WithSplit(&pbbot.SplitOptions{MaxChars: 3, ForwardThreshold: 2}), pbbot.WithMessageIds(&ids))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests = sendRequests(client.Requests())
+	if len(ids) != 1 || len(requests) != 4 {
+		t.Fatalf("expected one forward message, ids: %v, requests: %d", ids, len(requests))
+	}
+	nodes := pbbot.ParseForwardMsg(requests[3].GetSendPrivateMsgReq().Message).Nodes
+	if len(nodes) != 3 || nodes[0].Name != "30001" || nodes[0].Uin != 30001 {
+		t.Errorf("unexpected forward nodes: %+v", nodes)
+	}
+
+	// 发送间隔中 ctx 取消时停止
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err = bot.Send(ctx, pbbot.Group(1), pbbot.NewMsg().Text("abcdefg"),
+		pbbot.WithSplit(&pbbot.SplitOptions{MaxChars: 3, Delay: time.Hour}), pbbot.WithMessageIds(&ids))
+	if err != context.Canceled || len(ids) != 1 {
+		t.Errorf("expected cancel after first part, err: %v, ids: %v", err, ids)
+	}
+}
+
+func TestSendTarget(t *testing.T) {
+	client := newFakeBot(t, 33001, messageIdResponder())
+	bot := client.bot
+
+	targets := []struct {
+		target      pbbot.Target
+		str         string
+		messageType string
+		userId      int64
+		groupId     int64
+	}{
+		{pbbot.Group(100), "group:100", pbbot.MessageTypeGroup, 0, 100},
+		{pbbot.User(200), "private:200", pbbot.MessageTypePrivate, 200, 0},
+		{pbbot.TempFromGroup(100, 200), "temp:100:200", pbbot.MessageTypePrivate, 200, 100},
+	}
+	for i, c := range targets {
+		if c.target.String() != c.str {
+			t.Errorf("unexpected target string: %s", c.target)
+		}
+		resp, err := bot.Send(context.Background(), c.target, pbbot.NewMsg().Text("hi"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.MessageId != int32(i+1) {
+			t.Errorf("%s: unexpected message id %d", c.target, resp.MessageId)
+		}
+		requests := sendRequests(client.Requests())
+		req := requests[len(requests)-1]
+		if req.FrameType != onebot.Frame_TSendMsgReq {
+			t.Fatalf("%s: unexpected frame type %s", c.target, req.FrameType)
+		}
+		sendReq := req.GetSendMsgReq()
+		if sendReq.MessageType != c.messageType || sendReq.UserId != c.userId || sendReq.GroupId != c.groupId {
+			t.Errorf("%s: unexpected request %+v", c.target, sendReq)
+		}
+	}
+
+	// 构造消息出错时不发送
+	_, err := bot.Send(context.Background(), pbbot.Group(100), pbbot.NewMsg().ImageFile("/nonexistent/a.png"))
+	if err == nil {
+		t.Error("expected build error")
+	}
+	if n := len(sendRequests(client.Requests())); n != len(targets) {
+		t.Errorf("message with error should not be sent, requests: %d", n)
+	}
+}
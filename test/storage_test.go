@@ -0,0 +1,97 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtobufBot/go-pbbot/storage"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := storage.NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket := storage.NewBucket(store, storage.GroupNamespace(1, 2))
+	for i := 0; i < 3; i++ {
+		if _, err := bucket.Incr("counter", 1, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bucket.SetJSON("settings", map[string]bool{"enabled": true}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := bucket.Set("temp", []byte("x"), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := bucket.Get("temp"); err != storage.ErrNotFound {
+		t.Errorf("expected expired key, got %v", err)
+	}
+
+	reopened, err := storage.NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket = storage.NewBucket(reopened, storage.GroupNamespace(1, 2))
+	if counter, err := bucket.Get("counter"); err != nil || string(counter) != "3" {
+		t.Errorf("unexpected counter: %s %v", counter, err)
+	}
+	var settings map[string]bool
+	if err := bucket.GetJSON("settings", &settings); err != nil || !settings["enabled"] {
+		t.Errorf("unexpected settings: %+v %v", settings, err)
+	}
+	if keys, _ := storage.NewBucket(reopened, storage.GroupNamespace(1, 3)).Keys(); len(keys) != 0 {
+		t.Errorf("namespaces not isolated: %+v", keys)
+	}
+}
+
+func TestFileStoreSaveError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewFileStore(filepath.Join(dir, "store.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket := storage.NewBucket(store, "ns")
+	if err := bucket.Set("a", []byte("old"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bucket.Incr("n", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// 保存失败时内存中的数据保持修改前的状态
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := bucket.Set("a", []byte("new"), 0); err == nil {
+		t.Fatal("expected save error")
+	}
+	if err := bucket.Set("b", []byte("new"), 0); err == nil {
+		t.Fatal("expected save error")
+	}
+	if _, err := bucket.Incr("n", 1, 0); err == nil {
+		t.Fatal("expected save error")
+	}
+	if err := bucket.Delete("a"); err == nil {
+		t.Fatal("expected save error")
+	}
+	if value, err := bucket.Get("a"); err != nil || string(value) != "old" {
+		t.Errorf("a should be rolled back: %s %v", value, err)
+	}
+	if value, err := bucket.Get("n"); err != nil || string(value) != "1" {
+		t.Errorf("n should be rolled back: %s %v", value, err)
+	}
+	if _, err := bucket.Get("b"); err != storage.ErrNotFound {
+		t.Errorf("b should not be set: %v", err)
+	}
+	if keys, _ := bucket.Keys(); len(keys) != 2 || keys[0] != "a" || keys[1] != "n" {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+}
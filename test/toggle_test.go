@@ -0,0 +1,132 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/plugin"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/ProtobufBot/go-pbbot/storage"
+)
+
+func TestToggleRestore(t *testing.T) {
+	store := storage.NewMemoryStore()
+	manager := plugin.NewManager(store, nil)
+	manager.AddFeature("weather43")
+	if err := manager.Disable("weather43", 4301); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Disable("weather43", 4302); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Enable("weather43", 4302); err != nil {
+		t.Fatal(err)
+	}
+
+	// 新的 Manager 从同一个 Store 恢复开关状态
+	restored := plugin.NewManager(store, nil)
+	if restored.Enabled("weather43", 4301) || !restored.Enabled("weather43", 4302) {
+		t.Errorf("toggles not restored: %v", restored.DisabledGroups("weather43"))
+	}
+	if groups := restored.DisabledGroups("weather43"); len(groups) != 1 || groups[0] != 4301 {
+		t.Errorf("disabled groups: %v", groups)
+	}
+	if !restored.Enabled("weather43", 0) {
+		t.Error("feature should always be enabled in private chat")
+	}
+
+	if err := restored.Enable("weather43", 4301); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := store.Keys("plugin/disabled"); len(keys) != 0 {
+		t.Errorf("toggle should be deleted when no group is disabled: %v", keys)
+	}
+	if !plugin.NewManager(store, nil).Enabled("weather43", 4301) {
+		t.Error("enable should be persisted")
+	}
+}
+
+func TestToggleCommands(t *testing.T) {
+	const botId, superUser = 43001, 4309
+	manager := plugin.NewManager(storage.NewMemoryStore(), nil)
+	defer manager.Shutdown(context.Background())
+	if err := manager.Register(context.Background(), &testPlugin{name: "echo43", init: func(r *plugin.Registrar) {}}); err != nil {
+		t.Fatal(err)
+	}
+	manager.AddFeature("weather43")
+	manager.InstallCommands()
+	defer func() {
+		pbbot.UnregisterCommand("enable")
+		pbbot.UnregisterCommand("disable")
+		pbbot.UnregisterCommand("features")
+	}()
+	pbbot.SuperUsers[superUser] = true
+	defer delete(pbbot.SuperUsers, superUser)
+
+	client := newFakeBot(t, botId, messageIdResponder())
+	replies := 0
+	expectReply := func(text string) {
+		t.Helper()
+		replies++
+		waitFor(t, func() bool { return len(sendRequests(client.Requests())) >= replies })
+		req := sendRequests(client.Requests())[replies-1]
+		if got := pbbot.ParseMsg(req.GetSendMsgReq().GetMessage()).PlainText(); got != text {
+			t.Errorf("got reply %q, want %q", got, text)
+		}
+	}
+	pushGroup := func(userId int64, role string, text string) {
+		client.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{
+			GroupMessageEvent: groupMessage(4301, userId, role, text),
+		}})
+	}
+	pushPrivate := func(userId int64, text string) {
+		client.push(&onebot.Frame{FrameType: onebot.Frame_TPrivateMessageEvent, Data: &onebot.Frame_PrivateMessageEvent{PrivateMessageEvent: &onebot.PrivateMessageEvent{
+			UserId:  userId,
+			Message: pbbot.NewMsg().Text(text).MessageList,
+		}}})
+	}
+
+	// 群管理员在群中开关功能
+	pushGroup(1, pbbot.RoleAdmin, "/disable weather43")
+	expectReply("已在群 4301 禁用 weather43")
+	if manager.Enabled("weather43", 4301) {
+		t.Error("weather43 should be disabled in group 4301")
+	}
+	pushGroup(1, pbbot.RoleAdmin, "/features")
+	expectReply("echo43: 启用\nweather43: 禁用")
+
+	// 普通成员没有权限
+	pushGroup(2, pbbot.RoleMember, "/enable weather43")
+	expectReply("权限不足")
+	if manager.Enabled("weather43", 4301) {
+		t.Error("member should not enable weather43")
+	}
+	pushGroup(1, pbbot.RoleAdmin, "/enable weather43")
+	expectReply("已在群 4301 启用 weather43")
+	if !manager.Enabled("weather43", 4301) {
+		t.Error("weather43 should be enabled in group 4301")
+	}
+	pushGroup(1, pbbot.RoleAdmin, "/disable unknown43")
+	expectReply("功能 unknown43 不存在")
+
+	// 群管理员指定的群号被忽略，只作用于当前群
+	pushGroup(1, pbbot.RoleAdmin, "/disable echo43 4302")
+	expectReply("已在群 4301 禁用 echo43")
+	if !manager.Enabled("echo43", 4302) || manager.Enabled("echo43", 4301) {
+		t.Errorf("admin should only toggle own group: %v", manager.DisabledGroups("echo43"))
+	}
+
+	// 超级用户可以在私聊中指定群号
+	pushPrivate(superUser, "/disable weather43 4302")
+	expectReply("已在群 4302 禁用 weather43")
+	if manager.Enabled("weather43", 4302) {
+		t.Error("super user should disable weather43 in group 4302")
+	}
+	pushPrivate(superUser, "/features 4302")
+	expectReply("echo43: 启用\nweather43: 禁用")
+	pushPrivate(superUser, "/features")
+	expectReply("echo43: 禁用于 4301\nweather43: 禁用于 4302")
+	pushPrivate(superUser, "/enable weather43")
+	expectReply("请在群中使用或指定群号")
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"github.com/ProtobufBot/go-pbbot/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingSpans(t *testing.T) {
+	const botId = 46001
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))).Install()
+	pbbot.UseApiInterceptor(func(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+		if bot.BotId == botId && req.FrameType == onebot.Frame_TGetStrangerInfoReq {
+			return nil, errors.New("stranger not found")
+		}
+		return next(ctx)
+	})
+	pbbot.UseMessageHandler(func(ctx *pbbot.MessageContext) {
+		if ctx.Bot.BotId == botId {
+			_, _ = ctx.Bot.GetLoginInfo()
+			_, _ = ctx.Bot.GetStrangerInfo(ctx.UserId, false)
+		}
+	})
+	client := newFakeBot(t, botId, nil)
+
+	// 只保留当前机器人的 span
+	spans := func() map[string]tracetest.SpanStub {
+		result := make(map[string]tracetest.SpanStub)
+		for _, span := range exporter.GetSpans() {
+			for _, attr := range span.Attributes {
+				if attr.Key == tracing.AttrBotId && attr.Value.AsInt64() == botId {
+					result[span.Name] = span
+				}
+			}
+		}
+		return result
+	}
+	attrs := func(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+		result := make(map[attribute.Key]attribute.Value)
+		for _, attr := range span.Attributes {
+			result[attr.Key] = attr.Value
+		}
+		return result
+	}
+
+	client.push(&onebot.Frame{FrameType: onebot.Frame_TGroupMessageEvent, Data: &onebot.Frame_GroupMessageEvent{
+		GroupMessageEvent: groupMessage(100, 1, pbbot.RoleMember, "hi"),
+	}})
+	waitFor(t, func() bool { _, ok := spans()["pbbot.event TGroupMessageEvent"]; return ok })
+
+	all := spans()
+	event := all["pbbot.event TGroupMessageEvent"]
+	if event.SpanKind != trace.SpanKindConsumer || attrs(event)[tracing.AttrFrameType].AsString() != "TGroupMessageEvent" {
+		t.Errorf("unexpected event span: %+v", event)
+	}
+	api, ok := all["pbbot.api TGetLoginInfoReq"]
+	if !ok {
+		t.Fatal("missing api span")
+	}
+	if api.Parent.SpanID() != event.SpanContext.SpanID() || api.SpanContext.TraceID() != event.SpanContext.TraceID() {
+		t.Error("api span should be a child of the event span")
+	}
+	if api.SpanKind != trace.SpanKindClient || attrs(api)[tracing.AttrFrameType].AsString() != "TGetLoginInfoReq" ||
+		attrs(api)[tracing.AttrEcho].AsString() == "" || api.Status.Code == codes.Error {
+		t.Errorf("unexpected api span: %+v", api)
+	}
+	failed, ok := all["pbbot.api TGetStrangerInfoReq"]
+	if !ok {
+		t.Fatal("missing failed api span")
+	}
+	if failed.Status.Code != codes.Error || failed.Status.Description != "stranger not found" || len(failed.Events) != 1 {
+		t.Errorf("failed api span should record error: %+v", failed.Status)
+	}
+}
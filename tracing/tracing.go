@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ProtobufBot/go-pbbot"
+	"github.com/ProtobufBot/go-pbbot/proto_gen/onebot"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/ProtobufBot/go-pbbot/tracing"
+
+// span 属性
+const (
+	AttrBotId     = attribute.Key("pbbot.bot_id")
+	AttrFrameType = attribute.Key("pbbot.frame_type")
+	AttrEcho      = attribute.Key("pbbot.echo")
+)
+
+// Tracing 为事件处理和API调用创建 span，事件处理函数中的API调用作为事件 span 的子 span
+type Tracing struct {
+	tracer trace.Tracer
+}
+
+// New provider 为 nil 时使用 otel.GetTracerProvider()
+func New(provider trace.TracerProvider) *Tracing {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracing{tracer: provider.Tracer(instrumentationName)}
+}
+
+// Install 注册帧和API拦截器，只需要调用一次
+func (t *Tracing) Install() {
+	pbbot.UseFrameInterceptor(t.interceptFrame)
+	pbbot.UseApiInterceptor(t.interceptApi)
+}
+
+// interceptFrame API响应帧由 interceptApi 的 span 覆盖，这里只处理事件
+func (t *Tracing) interceptFrame(ctx context.Context, bot *pbbot.Bot, frame *onebot.Frame, next func(ctx context.Context)) {
+	if frame.FrameType >= 300 {
+		next(ctx)
+		return
+	}
+	ctx, span := t.tracer.Start(ctx, "pbbot.event "+frame.FrameType.String(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			AttrBotId.Int64(bot.BotId),
+			AttrFrameType.String(frame.FrameType.String()),
+		),
+	)
+	defer func() {
+		if e := recover(); e != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("panic: %+v", e))
+			span.End()
+			panic(e)
+		}
+		span.End()
+	}()
+	next(ctx)
+}
+
+func (t *Tracing) interceptApi(ctx context.Context, bot *pbbot.Bot, req *onebot.Frame, next func(ctx context.Context) (*onebot.Frame, error)) (*onebot.Frame, error) {
+	ctx, span := t.tracer.Start(ctx, "pbbot.api "+req.FrameType.String(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttrBotId.Int64(bot.BotId),
+			AttrFrameType.String(req.FrameType.String()),
+			AttrEcho.String(req.Echo),
+		),
+	)
+	defer span.End()
+	resp, err := next(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if !resp.Ok {
+		span.SetStatus(codes.Error, "resp frame not ok")
+	}
+	return resp, err
+}
@@ -10,13 +10,18 @@ import (
 
 var GlobalId int64 = 1
 
+// HandlePanic SafeGo 恢复 panic 后调用
+var HandlePanic = func(e interface{}, stack []byte) {
+	log.Errorf("err recovered: %+v", e)
+	log.Errorf("%s", stack)
+}
+
 func SafeGo(fn func()) {
 	go func() {
 		defer func() {
 			e := recover()
 			if e != nil {
-				log.Errorf("err recovered: %+v", e)
-				log.Errorf("%s", debug.Stack())
+				HandlePanic(e, debug.Stack())
 			}
 		}()
 		fn()
@@ -0,0 +1,55 @@
+package zaplog
+
+import (
+	"github.com/ProtobufBot/go-pbbot"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type zapLogger struct {
+	logger *zap.Logger
+}
+
+// New 把 zap.Logger 适配为 pbbot.Logger，zap 没有 trace 等级，LevelTrace 按 debug 输出
+func New(logger *zap.Logger) pbbot.Logger {
+	return &zapLogger{logger: logger}
+}
+
+func (l *zapLogger) Enabled(level pbbot.Level) bool {
+	return l.logger.Core().Enabled(zapLevel(level))
+}
+
+func (l *zapLogger) Log(level pbbot.Level, msg string, fields ...pbbot.Field) {
+	if ce := l.logger.Check(zapLevel(level), msg); ce != nil {
+		ce.Write(zapFields(fields)...)
+	}
+}
+
+func (l *zapLogger) With(fields ...pbbot.Field) pbbot.Logger {
+	return &zapLogger{logger: l.logger.With(zapFields(fields)...)}
+}
+
+func zapLevel(level pbbot.Level) zapcore.Level {
+	switch level {
+	case pbbot.LevelTrace, pbbot.LevelDebug:
+		return zapcore.DebugLevel
+	case pbbot.LevelInfo:
+		return zapcore.InfoLevel
+	case pbbot.LevelWarn:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func zapFields(fields []pbbot.Field) []zap.Field {
+	result := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		if err, ok := field.Value.(error); ok {
+			result = append(result, zap.NamedError(field.Key, err))
+			continue
+		}
+		result = append(result, zap.Any(field.Key, field.Value))
+	}
+	return result
+}